@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
+	"time"
 )
 
 const (
@@ -26,13 +28,68 @@ type API interface {
 	// Fetch a single Account resource using the accountID.
 	Fetch(ctx context.Context, accountID string) (AccountData, error)
 
+	// List a page of Account resources. Accounts are decoded one at a time
+	// and passed to fn, so the page is never held in memory as a whole.
+	// Error returned by fn stops the listing and is returned by List.
+	List(ctx context.Context, options ListOptions, fn func(AccountData) error) error
+
 	// Delete an Account resource using the accountID and the current version number.
 	Delete(ctx context.Context, accountID string, version int64) error
+
+	// Health checks the status of the service.
+	Health(ctx context.Context) (HealthStatus, error)
+}
+
+// DocumentAPI is implemented by API instances created by this package. It
+// gives access to whole response documents, including their links and meta:
+//
+//	doc, err := client.(DocumentAPI).FetchDocument(ctx, accountID)
+type DocumentAPI interface {
+	// CreateDocument is like Create, but returns the whole response document.
+	CreateDocument(ctx context.Context, data AccountData) (Document[AccountData], error)
+
+	// FetchDocument is like Fetch, but returns the whole response document.
+	FetchDocument(ctx context.Context, accountID string) (Document[AccountData], error)
+
+	// ListPage is like List, but also returns links and meta of the page,
+	// which can be used to navigate to the other pages.
+	ListPage(ctx context.Context, options ListOptions, fn func(AccountData) error) (Page, error)
 }
 
+// Operation identifies the kind of a request sent to the API.
+type Operation string
+
+const (
+	OperationCreate Operation = "create"
+	OperationFetch  Operation = "fetch"
+	OperationList   Operation = "list"
+	OperationPatch  Operation = "patch"
+	OperationDelete Operation = "delete"
+	OperationHealth Operation = "health"
+)
+
 type api struct {
-	client     *http.Client
-	retryCount uint
+	client          *http.Client
+	retryCount      uint
+	clock           Clock
+	maxResponseSize int64
+	organisationID  string
+
+	compressRequests bool
+	compressMinSize  int
+
+	breaker *circuitBreaker
+
+	limiter    *rateLimiter
+	opLimiters map[Operation]*rateLimiter
+
+	bulkheads map[Operation]*bulkhead
+
+	flights *flightGroup
+	hedger  *hedger
+
+	timeout    time.Duration
+	opTimeouts map[Operation]time.Duration
 }
 
 func drainAndCloseHttpResponse(resp *http.Response) {
@@ -41,21 +98,58 @@ func drainAndCloseHttpResponse(resp *http.Response) {
 	resp.Body.Close()
 }
 
-func (a *api) httpDoRetry(req *http.Request, count uint) (*http.Response, error) {
+func isRetryableStatusCode(statusCode int) bool {
+	switch statusCode {
+	case 429, 500, 502, 503, 504:
+		return true
+	default:
+		return false
+	}
+}
+
+func (a *api) httpDoRetry(req *http.Request, op Operation, count uint) (*http.Response, error) {
+	limiters := a.limiters(op)
+
 	for i := uint(0); i < count; i++ {
+		for _, l := range limiters {
+			if err := l.wait(req.Context(), a.clock); err != nil {
+				return nil, err
+			}
+		}
+
+		if i > 0 && req.GetBody != nil {
+			// Previous attempt has consumed the body.
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+
 		resp, err := a.client.Do(req)
 		if err != nil {
 			return nil, err
 		}
 
-		switch resp.StatusCode {
-		case 429, 500, 503, 504:
-			drainAndCloseHttpResponse(resp)
-		default:
+		if resp.StatusCode == 429 {
+			retryAfter := parseRetryAfter(resp.Header.Get("Retry-After"), a.clock.Now())
+			for _, l := range limiters {
+				l.throttled(a.clock.Now(), retryAfter)
+			}
+		} else if resp.StatusCode < 500 {
+			for _, l := range limiters {
+				l.succeeded(a.clock.Now())
+			}
+		}
+
+		// The last response is handed out as well, so that the caller can
+		// tell why the retries ran out.
+		if !isRetryableStatusCode(resp.StatusCode) || i+1 == count {
 			return resp, nil
 		}
+		drainAndCloseHttpResponse(resp)
 
-		if err := backOff(req.Context(), i); err != nil {
+		if err := backOff(req.Context(), a.clock, i); err != nil {
 			return nil, err
 		}
 	}
@@ -63,6 +157,8 @@ func (a *api) httpDoRetry(req *http.Request, count uint) (*http.Response, error)
 }
 
 func parse400or409(resp *http.Response) error {
+	// GenericError covers both the flat Form3 error body and JSON:API error
+	// arrays.
 	var ret GenericError
 	if err := json.NewDecoder(resp.Body).Decode(&ret); err != nil {
 		return err
@@ -81,106 +177,390 @@ func parse403(resp *http.Response) error {
 	return newErrForbiden(ret)
 }
 
+// Maximum number of bytes of a non-JSON error body kept as an error message.
+const maxErrorMessageSize = 4096
+
+func readErrorBody(resp *http.Response) ([]byte, error) {
+	return io.ReadAll(io.LimitReader(resp.Body, maxErrorMessageSize))
+}
+
+// decodeGenericError decodes an error body regardless of its shape. Bodies
+// that aren't JSON, for ex. HTML pages rendered by proxies, end up as the
+// error message.
+func decodeGenericError(resp *http.Response) (GenericError, error) {
+	b, err := readErrorBody(resp)
+	if err != nil {
+		return GenericError{}, err
+	}
+
+	var ret GenericError
+	if err := json.Unmarshal(b, &ret); err != nil {
+		ret.ErrorMessage = strings.TrimSpace(string(b))
+	}
+	return ret, nil
+}
+
+func parse401(resp *http.Response) error {
+	b, err := readErrorBody(resp)
+	if err != nil {
+		return err
+	}
+
+	var ret ForbiddenError
+	if err := json.Unmarshal(b, &ret); err != nil {
+		ret.ErrorDescription = strings.TrimSpace(string(b))
+	}
+	return newErrUnauthorized(ret)
+}
+
 func parseError(resp *http.Response) error {
+	var newErr func(GenericError) error
+
 	switch resp.StatusCode {
 	case 400, 409:
 		return parse400or409(resp)
+	case 401:
+		return parse401(resp)
 	case 403:
 		return parse403(resp)
 	case 404:
 		return new(ErrNotFound)
+	case 405:
+		newErr = newErrMethodNotAllowed
+	case 406:
+		newErr = newErrNotAcceptable
+	case 415:
+		newErr = newErrUnsupportedMediaType
+	case 422:
+		newErr = newErrUnprocessableEntity
+	case 502:
+		newErr = newErrBadGateway
 	default:
 		return newErrHttp(resp.StatusCode)
 	}
+
+	ret, err := decodeGenericError(resp)
+	if err != nil {
+		return err
+	}
+	return newErr(ret)
 }
 
-func (a *api) httpDo(ctx context.Context, method, url string, body any, res any) error {
+// encodeBody serializes body and compresses it, if requested. Returns the
+// content coding of the result.
+func (a *api) encodeBody(body any) (*bytes.Buffer, string, error) {
 	var b bytes.Buffer
 	if err := json.NewEncoder(&b).Encode(body); err != nil {
-		return err
+		return nil, "", err
 	}
 
-	req, err := http.NewRequestWithContext(ctx, method, url, &b)
+	if !a.compressRequests || b.Len() < a.compressMinSize {
+		return &b, "", nil
+	}
+
+	z, err := gzipBytes(b.Bytes())
 	if err != nil {
-		return err
+		return nil, "", err
 	}
+	return z, "gzip", nil
+}
+
+// request describes a single API call.
+type request struct {
+	op     Operation
+	method string
+	url    string
+	// Headers added to the defaults.
+	header http.Header
+	body   any
+	res    any
+}
 
-	req.Header.Set("Accept", "application/vnd.api+json")
-	req.Header.Set("Accept-Encoding", "gzip")
-	req.Header.Set("Content-Type", "application/vnd.api+json")
-	// No need to set Content-Length, stdlib is aware that we passed bytes.Buffer.
+// response carries metadata of a successful API call.
+type response struct {
+	statusCode int
+	header     http.Header
+}
+
+// newRequest builds a request with headers tailored to its payload. Requests
+// without a body (GET, DELETE) carry neither the body nor its headers, as some
+// proxies reject those.
+func (a *api) newRequest(ctx context.Context, r *request) (*http.Request, error) {
+	var req *http.Request
 
-	resp, err := a.httpDoRetry(req, a.retryCount)
+	if r.body == nil {
+		var err error
+		req, err = http.NewRequestWithContext(ctx, r.method, r.url, http.NoBody)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Accept", "application/vnd.api+json")
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+		// Stdlib sends "Content-Length: 0" only for methods that are expected
+		// to have a payload, like POST.
+	} else {
+		b, contentEncoding, err := a.encodeBody(r.body)
+		if err != nil {
+			return nil, err
+		}
+
+		req, err = http.NewRequestWithContext(ctx, r.method, r.url, b)
+		if err != nil {
+			return nil, err
+		}
+
+		req.Header.Set("Accept", "application/vnd.api+json")
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+		req.Header.Set("Content-Type", "application/vnd.api+json")
+		if len(contentEncoding) > 0 {
+			req.Header.Set("Content-Encoding", contentEncoding)
+		}
+		// No need to set Content-Length, stdlib is aware that we passed bytes.Buffer.
+	}
+
+	for k, v := range r.header {
+		req.Header[k] = v
+	}
+	return req, nil
+}
+
+func (a *api) httpDo(ctx context.Context, op Operation, method, url string, body any, res any) error {
+	_, err := a.send(ctx, &request{
+		op:     op,
+		method: method,
+		url:    url,
+		body:   body,
+		res:    res,
+	})
+	return err
+}
+
+func (a *api) send(ctx context.Context, r *request) (response, error) {
+	ctx, cancel := a.withOperationTimeout(ctx, r.op)
+	defer cancel()
+
+	if b, ok := a.bulkheads[r.op]; ok {
+		if err := b.acquire(ctx, a.clock); err != nil {
+			return response{}, err
+		}
+		defer b.release()
+	}
+
+	if a.breaker == nil {
+		return a.do(ctx, r)
+	}
+
+	generation, err := a.breaker.allow()
 	if err != nil {
-		return err
+		return response{}, err
+	}
+	resp, err := a.do(ctx, r)
+	a.breaker.done(a.clock, generation, err)
+	return resp, err
+}
+
+func (a *api) do(ctx context.Context, r *request) (response, error) {
+	req, err := a.newRequest(ctx, r)
+	if err != nil {
+		return response{}, err
+	}
+
+	resp, err := a.httpDoRetry(req, r.op, a.retryCount)
+	if err != nil {
+		return response{}, err
 	}
 	defer drainAndCloseHttpResponse(resp)
 
+	if err := decodeResponseBody(resp); err != nil {
+		return response{}, err
+	}
+	limitResponseBody(resp, a.maxResponseSize)
+
 	switch resp.StatusCode {
-	case 200, 201, 204:
+	case 200, 201, 204, 304:
 	default:
-		return parseError(resp)
+		err := parseError(resp)
+		if isRetryableStatusCode(resp.StatusCode) {
+			err = &ErrTooManyRetries{StatusCode: resp.StatusCode, Err: err}
+		}
+		return response{}, err
 	}
 
-	if res != nil && resp.StatusCode != 204 {
-		if err := json.NewDecoder(resp.Body).Decode(res); err != nil {
-			return err
+	switch res := r.res.(type) {
+	case nil:
+	case streamDecoder:
+		if resp.StatusCode == 200 {
+			if err := res.decodeStream(json.NewDecoder(resp.Body)); err != nil {
+				return response{}, err
+			}
+		}
+	default:
+		if resp.StatusCode != 204 && resp.StatusCode != 304 {
+			if err := json.NewDecoder(resp.Body).Decode(res); err != nil {
+				return response{}, err
+			}
 		}
 	}
-	return nil
+
+	return response{
+		statusCode: resp.StatusCode,
+		header:     resp.Header,
+	}, nil
 }
 
-func (a *api) Create(ctx context.Context, data AccountData) (AccountData, error) {
-	var ret struct {
-		Data AccountData
-	}
+// createDocument sends a create request of a resource, which is wrapped in
+// a JSON:API document.
+func createDocument[T any](ctx context.Context, a *api, url string, data T) (Document[T], error) {
+	var ret Document[T]
 
 	if err := a.httpDo(
 		ctx,
+		OperationCreate,
 		http.MethodPost,
-		fmt.Sprintf("%s/v1/organisation/accounts", BaseURL),
-		&struct{ Data AccountData }{Data: data},
+		url,
+		&Document[T]{Data: data},
 		&ret,
 	); err != nil {
-		return AccountData{}, err
+		return Document[T]{}, err
 	}
 
-	return ret.Data, nil
+	return ret, nil
 }
 
-func (a *api) Fetch(ctx context.Context, accountID string) (AccountData, error) {
-	var ret struct {
-		Data AccountData
+func fetchDocument[T any](ctx context.Context, a *api, url string) (Document[T], error) {
+	var ret Document[T]
+
+	if err := a.httpDo(ctx, OperationFetch, http.MethodGet, url, nil, &ret); err != nil {
+		return Document[T]{}, err
 	}
 
-	if err := a.httpDo(
-		ctx,
-		http.MethodGet,
-		fmt.Sprintf("%s/v1/organisation/accounts/%s", BaseURL, accountID),
-		nil,
-		&ret,
-	); err != nil {
-		return AccountData{}, err
+	return ret, nil
+}
+
+func listPage[T any](ctx context.Context, a *api, url string, fn func(T) error) (Page, error) {
+	dec := &listDecoder[T]{fn: fn}
+
+	if err := a.httpDo(ctx, OperationList, http.MethodGet, url, nil, dec); err != nil {
+		return Page{}, err
 	}
 
-	return ret.Data, nil
+	return dec.page, nil
 }
 
-func (a *api) Delete(ctx context.Context, accountID string, version int64) error {
+func deleteResource(ctx context.Context, a *api, url string, version int64) error {
 	return a.httpDo(
 		ctx,
+		OperationDelete,
 		http.MethodDelete,
-		fmt.Sprintf(
-			"%s/v1/organisation/accounts/%s?version=%d",
-			BaseURL,
-			accountID,
-			version,
-		),
+		fmt.Sprintf("%s?version=%d", url, version),
 		nil,
 		nil,
 	)
 }
 
+func (a *api) Create(ctx context.Context, data AccountData) (AccountData, error) {
+	doc, err := a.CreateDocument(ctx, data)
+	if err != nil {
+		return AccountData{}, err
+	}
+	return doc.Data, nil
+}
+
+func (a *api) CreateDocument(ctx context.Context, data AccountData) (Document[AccountData], error) {
+	if len(data.OrganisationID) == 0 {
+		data.OrganisationID = a.organisationID
+	}
+
+	var ret Document[AccountData]
+	if err := a.httpDo(
+		ctx,
+		OperationCreate,
+		http.MethodPost,
+		fmt.Sprintf("%s/v1/organisation/accounts", BaseURL),
+		&Document[accountRequest]{Data: newAccountRequest(data)},
+		&ret,
+	); err != nil {
+		return Document[AccountData]{}, err
+	}
+	return ret, nil
+}
+
+func (a *api) Fetch(ctx context.Context, accountID string) (AccountData, error) {
+	if a.flights != nil {
+		return a.flights.do(ctx, accountID, func(ctx context.Context) (AccountData, error) {
+			return a.hedgedFetch(ctx, accountID)
+		})
+	}
+	return a.hedgedFetch(ctx, accountID)
+}
+
+func (a *api) hedgedFetch(ctx context.Context, accountID string) (AccountData, error) {
+	if a.hedger != nil {
+		return a.hedger.do(ctx, a.clock, func(ctx context.Context) (AccountData, error) {
+			return a.fetch(ctx, accountID)
+		})
+	}
+	return a.fetch(ctx, accountID)
+}
+
+func (a *api) fetch(ctx context.Context, accountID string) (AccountData, error) {
+	doc, err := a.FetchDocument(ctx, accountID)
+	if err != nil {
+		return AccountData{}, err
+	}
+	return doc.Data, nil
+}
+
+// FetchDocument isn't coalesced nor hedged, unlike Fetch.
+func (a *api) FetchDocument(ctx context.Context, accountID string) (Document[AccountData], error) {
+	return fetchDocument[AccountData](
+		ctx,
+		a,
+		fmt.Sprintf("%s/v1/organisation/accounts/%s", BaseURL, accountID),
+	)
+}
+
+// fetchIfNoneMatch fetches an account, unless its current representation
+// matches etag. Returns the entity tag of the fetched representation, if the
+// server supplied one.
+func (a *api) fetchIfNoneMatch(ctx context.Context, accountID, etag string) (AccountData, string, bool, error) {
+	var ret Document[AccountData]
+
+	header := make(http.Header)
+	if len(etag) > 0 {
+		header.Set("If-None-Match", etag)
+	}
+
+	resp, err := a.send(ctx, &request{
+		op:     OperationFetch,
+		method: http.MethodGet,
+		url:    fmt.Sprintf("%s/v1/organisation/accounts/%s", BaseURL, accountID),
+		header: header,
+		res:    &ret,
+	})
+	if err != nil {
+		return AccountData{}, "", false, err
+	}
+
+	if resp.statusCode == 304 {
+		return AccountData{}, etag, true, nil
+	}
+	return ret.Data, resp.header.Get("ETag"), false, nil
+}
+
+func (a *api) List(ctx context.Context, options ListOptions, fn func(AccountData) error) error {
+	_, err := a.ListPage(ctx, options, fn)
+	return err
+}
+
+func (a *api) ListPage(ctx context.Context, options ListOptions, fn func(AccountData) error) (Page, error) {
+	return listPage(ctx, a, fmt.Sprintf("%s/v1/organisation/accounts%s", BaseURL, options.encode()), fn)
+}
+
+func (a *api) Delete(ctx context.Context, accountID string, version int64) error {
+	return deleteResource(ctx, a, fmt.Sprintf("%s/v1/organisation/accounts/%s", BaseURL, accountID), version)
+}
+
 // WithHttpClient provides http.Client to be used by an API instance.
 func WithHttpClient(client *http.Client) func(*api) {
 	return func(a *api) {
@@ -195,12 +575,29 @@ func WithRetryCount(n uint) func(*api) {
 	}
 }
 
-// NewAPI creates an API object that uses http.DefaultClient and default
-// retry count (when throttled).
+// WithRequestCompression enables gzip compression of request bodies that are
+// at least minSize bytes long. Make sure the server accepts compressed
+// requests before turning it on.
+func WithRequestCompression(minSize int) func(*api) {
+	return func(a *api) {
+		a.compressRequests = true
+		a.compressMinSize = minSize
+	}
+}
+
+// NewAPI creates an API object that uses an http.Client with sane timeouts and
+// connection pooling, shared by all API instances, and default retry count
+// (when throttled).
 func NewAPI(options ...func(*api)) API {
+	return newAPI(options...)
+}
+
+func newAPI(options ...func(*api)) *api {
 	ret := &api{
-		client:     http.DefaultClient,
-		retryCount: DefaultRetryCount,
+		client:          defaultHttpClient,
+		retryCount:      DefaultRetryCount,
+		clock:           SystemClock,
+		maxResponseSize: DefaultMaxResponseSize,
 	}
 	for _, f := range options {
 		f(ret)
@@ -5,9 +5,6 @@ package form3api_test
 
 import (
 	"context"
-	"errors"
-	"net"
-	"net/url"
 	"reflect"
 	"testing"
 	"time"
@@ -16,31 +13,6 @@ import (
 	"github.com/ksinica/form3api"
 )
 
-func waitForURL(remoteUrl string, timeout time.Duration) bool {
-	u, err := url.Parse(remoteUrl)
-	if err != nil {
-		panic(err)
-	}
-
-	ctx, cf := context.WithTimeout(context.Background(), timeout)
-	defer cf()
-
-	var d net.Dialer
-	for {
-		conn, err := d.DialContext(ctx, "tcp", u.Host)
-		if err == nil {
-			conn.Close()
-			return true
-		}
-
-		if errors.Is(err, context.DeadlineExceeded) {
-			return false
-		}
-
-		// We could put some back off here, because of unnecessry spinning...
-	}
-}
-
 type accountAPITestState struct {
 	data form3api.AccountData
 }
@@ -120,9 +92,11 @@ func AccountAPIDeleteIntegrationTest(state *accountAPITestState) func(*testing.T
 }
 
 func TestAccountAPIIntegration(t *testing.T) {
-	if !waitForURL(form3api.BaseURL, 15*time.Second) {
-		t.Error("could not connect to the service")
-		t.FailNow()
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	if err := form3api.WaitReady(ctx, form3api.NewAPI(), form3api.WaitReadyOptions{}); err != nil {
+		t.Fatal("service is not ready:", err)
 	}
 
 	var state accountAPITestState
@@ -8,6 +8,7 @@ import (
 	"io"
 	"net/http"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
@@ -78,29 +79,77 @@ func newClientReturningStatusCode(statusCode int) *http.Client {
 	return newClientReturningStatusCodeAndBuffer(statusCode, nil)
 }
 
-func newContextWithImmediateTimer() context.Context {
-	return withNewTimer(context.Background(), func(d time.Duration) timer {
-		return new(immediateTimer)
-	})
+// newClientEchoingData responds with the data of the request document, or
+// with an empty resource, when there is no request body.
+func newClientEchoingData(urls *[]string) *http.Client {
+	return &http.Client{
+		Transport: &testRoundTripper{
+			roundTrip: func(req *http.Request) (*http.Response, error) {
+				*urls = append(*urls, req.Method+" "+req.URL.String())
+
+				body := []byte(`{"data": {"id": "foo"}}`)
+				if req.Method == http.MethodPost || req.Method == http.MethodPatch {
+					var err error
+					if body, err = io.ReadAll(req.Body); err != nil {
+						return nil, err
+					}
+				}
+				return &http.Response{
+					StatusCode: 200,
+					Body:       io.NopCloser(bytes.NewBuffer(body)),
+					Request:    req,
+				}, nil
+			},
+		},
+	}
 }
 
+// newClientRespondingWith responds with the message and copies the request
+// body into body.
+func newClientRespondingWith(message string, body *bytes.Buffer) *http.Client {
+	return &http.Client{
+		Transport: &testRoundTripper{
+			roundTrip: func(req *http.Request) (*http.Response, error) {
+				body.Reset()
+				if _, err := io.Copy(body, req.Body); err != nil {
+					return nil, err
+				}
+				return &http.Response{
+					StatusCode: 201,
+					Body:       io.NopCloser(bytes.NewBufferString(message)),
+					Request:    req,
+				}, nil
+			},
+		},
+	}
+}
+
+const testAccountMessage = `{
+	"data": {
+		"id": "0d209d7f-d07a-4542-947f-5885fddddae2",
+		"organisation_id": "ba61483c-d5c5-4f50-ae81-6b8c039bea43",
+		"type": "accounts"
+	}
+}`
+
 func TestApiCreateFetchDeleteRetry(t *testing.T) {
 	api := NewAPI(
+		WithClock(newTestClock()),
 		WithHttpClient(newClientReturningStatusCode(429)),
 		WithRetryCount(100),
 	)
 
-	_, err := api.Create(newContextWithImmediateTimer(), AccountData{})
+	_, err := api.Create(context.Background(), AccountData{})
 	if !errors.Is(err, new(ErrTooManyRetries)) {
 		t.Error("Create error type not expected:", reflect.TypeOf(err).String())
 	}
 
-	_, err = api.Fetch(newContextWithImmediateTimer(), "foo")
+	_, err = api.Fetch(context.Background(), "foo")
 	if !errors.Is(err, new(ErrTooManyRetries)) {
 		t.Error("Fetch error type not expected:", reflect.TypeOf(err).String())
 	}
 
-	err = api.Delete(newContextWithImmediateTimer(), "bar", 123)
+	err = api.Delete(context.Background(), "bar", 123)
 	if !errors.Is(err, new(ErrTooManyRetries)) {
 		t.Error("Delete error type not expected:", reflect.TypeOf(err).String())
 	}
@@ -115,6 +164,7 @@ func TestApiCreateBadRequestError(t *testing.T) {
 	buf := newBufferCloseWrapper(bytes.NewBufferString(message))
 
 	api := NewAPI(
+		WithClock(newTestClock()),
 		WithHttpClient(
 			newClientReturningStatusCodeAndBuffer(
 				400,
@@ -123,7 +173,7 @@ func TestApiCreateBadRequestError(t *testing.T) {
 		),
 	)
 
-	_, err := api.Create(newContextWithImmediateTimer(), AccountData{})
+	_, err := api.Create(context.Background(), AccountData{})
 	if !errors.Is(err, new(ErrBadRequest)) {
 		t.Error("error type not expected:", reflect.TypeOf(err).String())
 	}
@@ -142,6 +192,7 @@ func TestApiCreateConflictError(t *testing.T) {
 	buf := newBufferCloseWrapper(bytes.NewBufferString(message))
 
 	api := NewAPI(
+		WithClock(newTestClock()),
 		WithHttpClient(
 			newClientReturningStatusCodeAndBuffer(
 				409,
@@ -150,7 +201,7 @@ func TestApiCreateConflictError(t *testing.T) {
 		),
 	)
 
-	_, err := api.Create(newContextWithImmediateTimer(), AccountData{})
+	_, err := api.Create(context.Background(), AccountData{})
 	if !errors.Is(err, new(ErrConflict)) {
 		t.Error("error type not expected:", reflect.TypeOf(err).String())
 	}
@@ -177,6 +228,7 @@ func TestApiFetchNotFound(t *testing.T) {
 	buf := newBufferCloseWrapper(bytes.NewBufferString(message))
 
 	api := NewAPI(
+		WithClock(newTestClock()),
 		WithHttpClient(
 			newClientReturningStatusCodeAndBuffer(
 				404,
@@ -185,7 +237,7 @@ func TestApiFetchNotFound(t *testing.T) {
 		),
 	)
 
-	_, err := api.Fetch(newContextWithImmediateTimer(), "baz")
+	_, err := api.Fetch(context.Background(), "baz")
 	if !errors.Is(err, new(ErrNotFound)) {
 		t.Error("error type not expected:", reflect.TypeOf(err).String())
 	}
@@ -204,6 +256,7 @@ func TestApiDeleteForbiddenError(t *testing.T) {
 	buf := newBufferCloseWrapper(bytes.NewBufferString(message))
 
 	api := NewAPI(
+		WithClock(newTestClock()),
 		WithHttpClient(
 			newClientReturningStatusCodeAndBuffer(
 				403,
@@ -212,7 +265,7 @@ func TestApiDeleteForbiddenError(t *testing.T) {
 		),
 	)
 
-	err := api.Delete(newContextWithImmediateTimer(), "quux", 321)
+	err := api.Delete(context.Background(), "quux", 321)
 	if !errors.Is(err, new(ErrForbidden)) {
 		t.Error("error type not expected:", reflect.TypeOf(err).String())
 	}
@@ -293,6 +346,96 @@ func TestApiCreateSuccessfulRoundTrip(t *testing.T) {
 	}
 }
 
+func TestApiFetchDocument(t *testing.T) {
+	const message = `{
+		"data": {
+			"id": "0d209d7f-d07a-4542-947f-5885fddddae2",
+			"type": "accounts",
+			"created_on": "2022-01-02T10:20:30.123Z",
+			"modified_on": "2022-01-03T10:20:30.123Z",
+			"relationships": {
+				"master_account": {
+					"data": [{"type": "accounts", "id": "a52d13a4-f435-4c00-cfad-f5e7ac5972df"}]
+				},
+				"account_events": {
+					"data": {"type": "account_events", "id": "c1023677-70ee-417a-9a6a-e211241f1e9c"}
+				}
+			}
+		},
+		"links": {
+			"self": "/v1/organisation/accounts/0d209d7f-d07a-4542-947f-5885fddddae2"
+		},
+		"meta": {"foo": "bar"}
+	}`
+
+	api := NewAPI(
+		WithHttpClient(
+			newClientReturningStatusCodeAndBuffer(
+				200,
+				newBufferCloseWrapper(bytes.NewBufferString(message)),
+			),
+		),
+	)
+
+	doc, err := api.(DocumentAPI).FetchDocument(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2")
+	if err != nil {
+		t.Fatal("no error expected, got:", err)
+	}
+
+	if doc.Links == nil || doc.Links.Self != "/v1/organisation/accounts/0d209d7f-d07a-4542-947f-5885fddddae2" {
+		t.Error("unexpected links:", doc.Links)
+	}
+	if string(doc.Meta) != `{"foo": "bar"}` {
+		t.Error("unexpected meta:", string(doc.Meta))
+	}
+
+	createdOn := time.Date(2022, 1, 2, 10, 20, 30, 123000000, time.UTC)
+	if doc.Data.CreatedOn == nil || !doc.Data.CreatedOn.Equal(createdOn) {
+		t.Error("unexpected created on:", doc.Data.CreatedOn)
+	}
+	if doc.Data.ModifiedOn == nil || !doc.Data.ModifiedOn.After(createdOn) {
+		t.Error("unexpected modified on:", doc.Data.ModifiedOn)
+	}
+
+	for name, expected := range map[string][]string{
+		"master_account": {"a52d13a4-f435-4c00-cfad-f5e7ac5972df"},
+		"account_events": {"c1023677-70ee-417a-9a6a-e211241f1e9c"},
+		"foo":            nil,
+	} {
+		if ids := doc.Data.RelationshipIDs(name); !reflect.DeepEqual(ids, expected) {
+			t.Errorf("%s: unexpected relationship ids: %v", name, ids)
+		}
+	}
+}
+
+func TestApiCreateOmitsReadOnlyFields(t *testing.T) {
+	var body bytes.Buffer
+	api := NewAPI(WithHttpClient(newClientRespondingWith(testAccountMessage, &body)))
+
+	now := time.Now()
+	_, err := api.Create(context.Background(), AccountData{
+		ID:         "0d209d7f-d07a-4542-947f-5885fddddae2",
+		Version:    Int64(0),
+		CreatedOn:  &now,
+		ModifiedOn: &now,
+		Relationships: map[string]Relationship{
+			"master_account": {Data: []ResourceIdentifier{{ID: "foo", Type: "accounts"}}},
+		},
+	})
+	if err != nil {
+		t.Fatal("no error expected, got:", err)
+	}
+
+	for _, field := range []string{"created_on", "modified_on", "relationships"} {
+		if strings.Contains(body.String(), field) {
+			t.Errorf("%s not expected in request body: %s", field, body.String())
+		}
+	}
+	if !strings.Contains(body.String(), `"version":0`) {
+		t.Error("expected version in request body:", body.String())
+	}
+}
+
 func TestApiCreateInvalidJsonResponse(t *testing.T) {
 	const message = `{
 		"data": {
@@ -320,3 +463,301 @@ func TestApiCreateInvalidJsonResponse(t *testing.T) {
 		t.Error("buffer was not drained and closed")
 	}
 }
+
+func TestApiCreateBadRequestJsonApiErrors(t *testing.T) {
+	const message = `{
+		"errors": [
+			{
+				"status": "400",
+				"code": "validation_failure",
+				"title": "Invalid field",
+				"detail": "bank_id in body should match '^[A-Z0-9]{0,16}$'",
+				"source": {"pointer": "/data/attributes/bank_id"}
+			},
+			{
+				"status": "400",
+				"title": "country in body is required",
+				"source": {"pointer": "/data/attributes/country"}
+			}
+		]
+	}`
+
+	buf := newBufferCloseWrapper(bytes.NewBufferString(message))
+
+	api := NewAPI(
+		WithClock(newTestClock()),
+		WithHttpClient(
+			newClientReturningStatusCodeAndBuffer(
+				400,
+				buf,
+			),
+		),
+	)
+
+	_, err := api.Create(context.Background(), AccountData{})
+
+	var e *ErrBadRequest
+	if !errors.As(err, &e) {
+		t.Fatal("error type not expected:", reflect.TypeOf(err).String())
+	}
+
+	if len(e.Errors) != 2 {
+		t.Fatal("unexpected number of errors:", len(e.Errors))
+	}
+	if attr := e.Errors[0].Source.Attribute(); attr != "bank_id" {
+		t.Error("unexpected attribute:", attr)
+	}
+
+	expected := map[string][]string{
+		"/data/attributes/bank_id": {"bank_id in body should match '^[A-Z0-9]{0,16}$'"},
+		"/data/attributes/country": {"country in body is required"},
+	}
+	if fields := e.FieldErrors(); !reflect.DeepEqual(fields, expected) {
+		t.Error("unexpected field errors:", fields)
+	}
+
+	if !IsValidation(err) {
+		t.Error("expected validation error")
+	}
+
+	if !buf.isDrainedAndClosed() {
+		t.Error("buffer was not drained and closed")
+	}
+}
+
+func TestApiParseErrorStatusCodes(t *testing.T) {
+	for _, test := range []struct {
+		statusCode int
+		body       string
+		expected   error
+		message    string
+	}{
+		{
+			statusCode: 401,
+			body:       `{"error": "invalid_token", "error_description": "Token expired."}`,
+			expected:   new(ErrUnauthorized),
+			message:    "invalid_token: Token expired.",
+		},
+		{
+			statusCode: 405,
+			body:       `{"error_message": "Method not allowed"}`,
+			expected:   new(ErrMethodNotAllowed),
+			message:    "Method not allowed",
+		},
+		{
+			statusCode: 406,
+			body:       `<html><body>Not Acceptable</body></html>`,
+			expected:   new(ErrNotAcceptable),
+			message:    "<html><body>Not Acceptable</body></html>",
+		},
+		{
+			statusCode: 415,
+			body:       `{"errors": [{"status": "415", "title": "Unsupported media type"}]}`,
+			expected:   new(ErrUnsupportedMediaType),
+			message:    "Unsupported media type",
+		},
+		{
+			statusCode: 422,
+			body:       `{"error_code": "x", "error_message": "Account number is invalid"}`,
+			expected:   new(ErrUnprocessableEntity),
+			message:    "x: Account number is invalid",
+		},
+		{
+			statusCode: 418,
+			expected:   &ErrHttp{StatusCode: 418},
+			message:    "418: I'm a teapot",
+		},
+	} {
+		buf := newBufferCloseWrapper(bytes.NewBufferString(test.body))
+
+		api := NewAPI(
+			WithClock(newTestClock()),
+			WithHttpClient(
+				newClientReturningStatusCodeAndBuffer(
+					test.statusCode,
+					buf,
+				),
+			),
+		)
+
+		_, err := api.Fetch(context.Background(), "foo")
+		if !errors.Is(err, test.expected) && !reflect.DeepEqual(err, test.expected) {
+			t.Errorf("%d: error type not expected: %s", test.statusCode, reflect.TypeOf(err).String())
+			continue
+		}
+		if err.Error() != test.message {
+			t.Errorf("%d: unexpected error message: %q", test.statusCode, err.Error())
+		}
+
+		if !buf.isDrainedAndClosed() {
+			t.Errorf("%d: buffer was not drained and closed", test.statusCode)
+		}
+	}
+}
+
+func TestApiRetryBadGateway(t *testing.T) {
+	var attempts int
+
+	api := NewAPI(
+		WithClock(newTestClock()),
+		WithHttpClient(&http.Client{
+			Transport: &testRoundTripper{
+				roundTrip: func(req *http.Request) (*http.Response, error) {
+					attempts++
+					return &http.Response{
+						StatusCode: 502,
+						Body: io.NopCloser(
+							bytes.NewBufferString("<html>502 Bad Gateway</html>"),
+						),
+						Request: req,
+					}, nil
+				},
+			},
+		}),
+		WithRetryCount(3),
+	)
+
+	_, err := api.Fetch(context.Background(), "foo")
+	if !errors.Is(err, &ErrTooManyRetries{StatusCode: 502}) {
+		t.Error("error type not expected:", reflect.TypeOf(err).String())
+	}
+	var badGateway *ErrBadGateway
+	if !errors.As(err, &badGateway) {
+		t.Fatal("expected bad gateway error, got:", err)
+	}
+	if badGateway.ErrorMessage != "<html>502 Bad Gateway</html>" {
+		t.Errorf("unexpected error message: %q", badGateway.ErrorMessage)
+	}
+	if !IsRetryable(err) {
+		t.Error("expected bad gateway to be retryable")
+	}
+	if attempts != 3 {
+		t.Error("unexpected number of attempts:", attempts)
+	}
+}
+
+func TestApiRetryExhausted(t *testing.T) {
+	for _, test := range []struct {
+		statusCode int
+		code       ErrorCode
+		category   ErrorCategory
+	}{
+		{statusCode: 429, code: CodeTooManyRequests, category: CategoryThrottling},
+		{statusCode: 500, code: CodeUnknown, category: CategoryUnknown},
+		{statusCode: 503, code: CodeUnknown, category: CategoryUnknown},
+	} {
+		api := NewAPI(
+			WithClock(newTestClock()),
+			WithHttpClient(newClientReturningStatusCode(test.statusCode)),
+			WithRetryCount(3),
+		)
+
+		_, err := api.Fetch(context.Background(), "foo")
+		if !errors.Is(err, new(ErrTooManyRetries)) {
+			t.Errorf("%d: error type not expected: %s", test.statusCode, reflect.TypeOf(err).String())
+			continue
+		}
+		var e *ErrHttp
+		if test.statusCode != 429 && (!errors.As(err, &e) || e.StatusCode != test.statusCode) {
+			t.Errorf("%d: expected the last error to be wrapped, got: %v", test.statusCode, err)
+		}
+		if Code(err) != test.code {
+			t.Errorf("%d: unexpected code: %q", test.statusCode, Code(err))
+		}
+		if Category(err) != test.category {
+			t.Errorf("%d: unexpected category: %s", test.statusCode, Category(err))
+		}
+	}
+}
+
+func newClientCapturingRequests(statusCodes []int, reqs *[]*http.Request, bodies *[]string) *http.Client {
+	return &http.Client{
+		Transport: &testRoundTripper{
+			roundTrip: func(req *http.Request) (*http.Response, error) {
+				var body bytes.Buffer
+				if req.Body != nil {
+					io.Copy(&body, req.Body)
+				}
+				*reqs = append(*reqs, req)
+				*bodies = append(*bodies, body.String())
+
+				statusCode := statusCodes[0]
+				if len(statusCodes) > 1 {
+					statusCodes = statusCodes[1:]
+				}
+				return &http.Response{
+					StatusCode: statusCode,
+					Body:       io.NopCloser(bytes.NewBufferString(`{"data": {}}`)),
+					Request:    req,
+				}, nil
+			},
+		},
+	}
+}
+
+func TestApiBodylessRequests(t *testing.T) {
+	var (
+		reqs   []*http.Request
+		bodies []string
+	)
+
+	api := NewAPI(
+		WithHttpClient(newClientCapturingRequests([]int{200}, &reqs, &bodies)),
+	)
+
+	if _, err := api.Fetch(context.Background(), "foo"); err != nil {
+		t.Fatal("no error expected, got:", err)
+	}
+	if err := api.Delete(context.Background(), "foo", 0); err != nil {
+		t.Fatal("no error expected, got:", err)
+	}
+
+	for i, req := range reqs {
+		if req.Body != nil && req.Body != http.NoBody {
+			t.Errorf("%s: unexpected body", req.Method)
+		}
+		if len(bodies[i]) > 0 {
+			t.Errorf("%s: unexpected body contents: %q", req.Method, bodies[i])
+		}
+		if req.ContentLength != 0 {
+			t.Errorf("%s: unexpected content length: %d", req.Method, req.ContentLength)
+		}
+		if req.Header.Get("Content-Type") != "" {
+			t.Errorf("%s: unexpected content type", req.Method)
+		}
+		if req.Header.Get("Accept") != "application/vnd.api+json" {
+			t.Errorf("%s: unexpected accept header", req.Method)
+		}
+	}
+}
+
+func TestApiCreateRetryResendsBody(t *testing.T) {
+	var (
+		reqs   []*http.Request
+		bodies []string
+	)
+
+	api := NewAPI(
+		WithClock(newTestClock()),
+		WithHttpClient(newClientCapturingRequests([]int{503, 201}, &reqs, &bodies)),
+	)
+
+	if _, err := api.Create(context.Background(), AccountData{ID: "foo"}); err != nil {
+		t.Fatal("no error expected, got:", err)
+	}
+
+	if len(reqs) != 2 {
+		t.Fatal("unexpected number of requests:", len(reqs))
+	}
+	for i, req := range reqs {
+		if req.Header.Get("Content-Type") != "application/vnd.api+json" {
+			t.Error("unexpected content type:", req.Header.Get("Content-Type"))
+		}
+		if req.ContentLength != int64(len(bodies[i])) || len(bodies[i]) == 0 {
+			t.Errorf("unexpected content length %d of body %q", req.ContentLength, bodies[i])
+		}
+	}
+	if bodies[0] != bodies[1] {
+		t.Errorf("retried body differs: %q != %q", bodies[0], bodies[1])
+	}
+}
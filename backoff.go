@@ -12,46 +12,8 @@ const (
 	backOffJitterMs = 100
 )
 
-type timer interface {
-	Tick() <-chan time.Time
-	Stop() bool
-}
-
-type timeTimer struct {
-	*time.Timer
-}
-
-func (t *timeTimer) Tick() <-chan time.Time {
-	return t.Timer.C
-}
-
-func (t *timeTimer) Stop() bool {
-	return t.Timer.Stop()
-}
-
-func newTimer(d time.Duration) timer {
-	return &timeTimer{
-		Timer: time.NewTimer(d),
-	}
-}
-
-type ctxNewTimer struct{}
-
-type newTimerFunc func(time.Duration) timer
-
-func withNewTimer(ctx context.Context, f newTimerFunc) context.Context {
-	return context.WithValue(ctx, ctxNewTimer{}, f)
-}
-
-func mustGetNewTimer(ctx context.Context) newTimerFunc {
-	if value := ctx.Value(ctxNewTimer{}); value != nil {
-		return value.(newTimerFunc)
-	}
-	return newTimer
-}
-
-func sleepContext(ctx context.Context, duration time.Duration) error {
-	delay := mustGetNewTimer(ctx)(duration)
+func sleepContext(ctx context.Context, clock Clock, duration time.Duration) error {
+	delay := clock.NewTimer(duration)
 	select {
 	case <-delay.Tick():
 		return nil
@@ -71,8 +33,8 @@ func max(a, b int) int {
 	return b
 }
 
-func backOff(ctx context.Context, n uint) error {
+func backOff(ctx context.Context, clock Clock, n uint) error {
 	d := int(math.Round(math.Pow(1.5, float64(n)) * 500.0))
 	d = max(minBackOffMs, d+(rand.Intn(backOffJitterMs)-backOffJitterMs/2))
-	return sleepContext(ctx, time.Duration(d)*time.Millisecond)
+	return sleepContext(ctx, clock, time.Duration(d)*time.Millisecond)
 }
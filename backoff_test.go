@@ -3,6 +3,7 @@ package form3api
 import (
 	"context"
 	"math"
+	"sync"
 	"testing"
 	"time"
 )
@@ -19,6 +20,43 @@ func (t *immediateTimer) Stop() bool {
 	return true
 }
 
+// testClock is a Clock, which time moves only when advanced. Unless newTimer
+// is set, its timers fire immediately.
+type testClock struct {
+	mu       sync.Mutex
+	now      time.Time
+	newTimer func(time.Duration) Timer
+}
+
+func newTestClock() *testClock {
+	return &testClock{
+		now: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func (c *testClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *testClock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func (c *testClock) NewTimer(d time.Duration) Timer {
+	if c.newTimer != nil {
+		return c.newTimer(d)
+	}
+	return new(immediateTimer)
+}
+
+func (c *testClock) After(d time.Duration) <-chan time.Time {
+	return c.NewTimer(d).Tick()
+}
+
 func TestBackOff(t *testing.T) {
 	const (
 		tolerance = backOffJitterMs * time.Millisecond
@@ -41,14 +79,15 @@ func TestBackOff(t *testing.T) {
 		{input: 8, expected: 12814},
 		{input: 9, expected: 19222},
 	} {
-		ctx := withNewTimer(context.Background(), func(d time.Duration) timer {
+		clock := newTestClock()
+		clock.newTimer = func(d time.Duration) Timer {
 			expected := time.Duration(test.expected) * time.Millisecond
 			if math.Abs(d.Seconds()-expected.Seconds()) > tolerance.Seconds() {
 				t.Errorf("back-off delay exceeded expected %d with %d", expected, d)
 			}
 			return new(immediateTimer)
-		})
+		}
 
-		backOff(ctx, test.input)
+		backOff(context.Background(), clock, test.input)
 	}
 }
@@ -0,0 +1,283 @@
+package form3api
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultBreakerConsecutiveFailures uint = 5
+	DefaultBreakerWindow              uint = 20
+	DefaultBreakerCoolDown                 = 30 * time.Second
+)
+
+// BreakerState is the state of a circuit breaker.
+type BreakerState int
+
+const (
+	// Requests flow normally, failures are being counted.
+	BreakerClosed BreakerState = iota
+	// Requests fail fast with ErrCircuitOpen until the cool-down passes.
+	BreakerOpen
+	// A limited number of probe requests is let through to check whether
+	// the service has recovered.
+	BreakerHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// CircuitBreakerSettings configures the circuit breaker installed with
+// WithCircuitBreaker. Zero values are replaced with defaults.
+type CircuitBreakerSettings struct {
+	// Number of consecutive failures after which the circuit opens. Defaults
+	// to DefaultBreakerConsecutiveFailures, unless FailureRate is set.
+	ConsecutiveFailures uint
+
+	// Ratio of failed requests, between 0 and 1, after which the circuit
+	// opens. Disabled when zero.
+	FailureRate float64
+
+	// Number of the most recent requests taken into account when computing
+	// the failure rate. The rate isn't checked until the window fills up.
+	Window uint
+
+	// How long the circuit stays open before letting probe requests through.
+	CoolDown time.Duration
+
+	// Number of concurrent probe requests allowed while half-open. Defaults
+	// to one.
+	HalfOpenRequests uint
+
+	// OnStateChange, if set, is called on every state transition. It must
+	// not block.
+	OnStateChange func(from, to BreakerState)
+}
+
+type breakerOutcome int
+
+const (
+	breakerSuccess breakerOutcome = iota
+	breakerFailure
+	// The request was cancelled by the caller, so it tells us nothing about
+	// the health of the service.
+	breakerIgnored
+)
+
+// isOutageError reports whether err indicates that the service is unhealthy,
+// as opposed to the request itself being wrong.
+func isOutageError(err error) bool {
+	// Errors of the last attempt are wrapped in ErrTooManyRetries, so that
+	// throttling isn't taken for an outage.
+	var (
+		badGateway *ErrBadGateway
+		httpErr    *ErrHttp
+		urlErr     *url.Error
+	)
+	switch {
+	case err == nil, errors.Is(err, context.Canceled):
+		return false
+	case errors.Is(err, context.DeadlineExceeded),
+		errors.As(err, &badGateway),
+		errors.As(err, &urlErr):
+		return true
+	case errors.As(err, &httpErr):
+		return httpErr.StatusCode >= 500
+	default:
+		return false
+	}
+}
+
+func breakerOutcomeOf(err error) breakerOutcome {
+	switch {
+	case isOutageError(err):
+		return breakerFailure
+	case errors.Is(err, context.Canceled):
+		return breakerIgnored
+	default:
+		return breakerSuccess
+	}
+}
+
+type circuitBreaker struct {
+	settings CircuitBreakerSettings
+
+	mu    sync.Mutex
+	state BreakerState
+	// Incremented on every state transition, so that outcomes of requests
+	// started in a different state can be told apart.
+	generation  uint64
+	consecutive uint
+	// Ring buffer of the most recent outcomes, true meaning a failure.
+	outcomes []bool
+	next     int
+	filled   bool
+	failures uint
+	probes   uint
+}
+
+func newCircuitBreaker(settings CircuitBreakerSettings) *circuitBreaker {
+	if settings.ConsecutiveFailures == 0 && settings.FailureRate == 0 {
+		settings.ConsecutiveFailures = DefaultBreakerConsecutiveFailures
+	}
+	if settings.Window == 0 {
+		settings.Window = DefaultBreakerWindow
+	}
+	if settings.CoolDown == 0 {
+		settings.CoolDown = DefaultBreakerCoolDown
+	}
+	if settings.HalfOpenRequests == 0 {
+		settings.HalfOpenRequests = 1
+	}
+
+	ret := &circuitBreaker{
+		settings: settings,
+	}
+	if settings.FailureRate > 0 {
+		ret.outcomes = make([]bool, settings.Window)
+	}
+	return ret
+}
+
+type breakerTransition struct {
+	from, to BreakerState
+}
+
+func (b *circuitBreaker) notify(t *breakerTransition) {
+	if t != nil && b.settings.OnStateChange != nil {
+		b.settings.OnStateChange(t.from, t.to)
+	}
+}
+
+// Must be called with b.mu held.
+func (b *circuitBreaker) setState(state BreakerState) *breakerTransition {
+	t := &breakerTransition{from: b.state, to: state}
+
+	b.state = state
+	b.generation++
+	b.consecutive = 0
+	b.next = 0
+	b.filled = false
+	b.failures = 0
+	b.probes = 0
+	for i := range b.outcomes {
+		b.outcomes[i] = false
+	}
+	return t
+}
+
+// Must be called with b.mu held.
+func (b *circuitBreaker) open(clock Clock) *breakerTransition {
+	t := b.setState(BreakerOpen)
+
+	generation := b.generation
+	coolDown := clock.After(b.settings.CoolDown)
+	go func() {
+		<-coolDown
+
+		b.mu.Lock()
+		var t *breakerTransition
+		if b.generation == generation {
+			t = b.setState(BreakerHalfOpen)
+		}
+		b.mu.Unlock()
+
+		b.notify(t)
+	}()
+	return t
+}
+
+// Must be called with b.mu held.
+func (b *circuitBreaker) record(failed bool) bool {
+	if failed {
+		b.consecutive++
+	} else {
+		b.consecutive = 0
+	}
+	if b.settings.ConsecutiveFailures > 0 && b.consecutive >= b.settings.ConsecutiveFailures {
+		return true
+	}
+
+	if b.outcomes == nil {
+		return false
+	}
+	if b.outcomes[b.next] {
+		b.failures--
+	}
+	b.outcomes[b.next] = failed
+	if failed {
+		b.failures++
+	}
+	b.next = (b.next + 1) % len(b.outcomes)
+	if b.next == 0 {
+		b.filled = true
+	}
+	return b.filled && float64(b.failures)/float64(len(b.outcomes)) >= b.settings.FailureRate
+}
+
+// allow checks whether a request may be sent. Returned generation has to be
+// passed to done, once the request completes.
+func (b *circuitBreaker) allow() (uint64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		return 0, new(ErrCircuitOpen)
+	case BreakerHalfOpen:
+		if b.probes >= b.settings.HalfOpenRequests {
+			return 0, new(ErrCircuitOpen)
+		}
+		b.probes++
+	}
+	return b.generation, nil
+}
+
+func (b *circuitBreaker) done(clock Clock, generation uint64, err error) {
+	outcome := breakerOutcomeOf(err)
+
+	b.mu.Lock()
+	var t *breakerTransition
+	if b.generation == generation {
+		switch b.state {
+		case BreakerClosed:
+			if outcome != breakerIgnored && b.record(outcome == breakerFailure) {
+				t = b.open(clock)
+			}
+		case BreakerHalfOpen:
+			switch outcome {
+			case breakerSuccess:
+				t = b.setState(BreakerClosed)
+			case breakerFailure:
+				t = b.open(clock)
+			default:
+				b.probes--
+			}
+		}
+	}
+	b.mu.Unlock()
+
+	b.notify(t)
+}
+
+// WithCircuitBreaker makes an API instance stop sending requests for a while,
+// when the service appears to be down. Requests rejected by the open circuit
+// fail with ErrCircuitOpen.
+func WithCircuitBreaker(settings CircuitBreakerSettings) func(*api) {
+	return func(a *api) {
+		a.breaker = newCircuitBreaker(settings)
+	}
+}
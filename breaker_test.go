@@ -0,0 +1,218 @@
+package form3api
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type manualTimer struct {
+	c chan time.Time
+}
+
+func (t *manualTimer) Tick() <-chan time.Time {
+	return t.c
+}
+
+func (t *manualTimer) Stop() bool {
+	return true
+}
+
+func (t *manualTimer) fire() {
+	t.c <- time.Now()
+}
+
+type breakerTestState struct {
+	statusCode int32
+	attempts   int32
+	timer      *manualTimer
+	states     chan BreakerState
+}
+
+func (s *breakerTestState) client() *http.Client {
+	return &http.Client{
+		Transport: &testRoundTripper{
+			roundTrip: func(req *http.Request) (*http.Response, error) {
+				atomic.AddInt32(&s.attempts, 1)
+				return &http.Response{
+					StatusCode: int(atomic.LoadInt32(&s.statusCode)),
+					Body:       io.NopCloser(bytes.NewBufferString(`{"data": {}}`)),
+					Request:    req,
+				}, nil
+			},
+		},
+	}
+}
+
+func (s *breakerTestState) clock() Clock {
+	clock := newTestClock()
+	clock.newTimer = func(d time.Duration) Timer {
+		if d == DefaultBreakerCoolDown {
+			return s.timer
+		}
+		return new(immediateTimer)
+	}
+	return clock
+}
+
+func (s *breakerTestState) expectState(t *testing.T, expected BreakerState) {
+	t.Helper()
+	select {
+	case state := <-s.states:
+		if state != expected {
+			t.Fatalf("expected %s state, got %s", expected, state)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("expected %s state, got nothing", expected)
+	}
+}
+
+func newBreakerTestState(statusCode int) *breakerTestState {
+	return &breakerTestState{
+		statusCode: int32(statusCode),
+		timer:      &manualTimer{c: make(chan time.Time)},
+		states:     make(chan BreakerState, 16),
+	}
+}
+
+func TestCircuitBreakerConsecutiveFailures(t *testing.T) {
+	s := newBreakerTestState(500)
+
+	api := NewAPI(
+		WithHttpClient(s.client()),
+		WithClock(s.clock()),
+		WithRetryCount(1),
+		WithCircuitBreaker(CircuitBreakerSettings{
+			ConsecutiveFailures: 3,
+			OnStateChange: func(from, to BreakerState) {
+				s.states <- to
+			},
+		}),
+	)
+
+	for i := 0; i < 3; i++ {
+		if _, err := api.Fetch(context.Background(), "foo"); !errors.Is(err, new(ErrTooManyRetries)) {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+	s.expectState(t, BreakerOpen)
+
+	_, err := api.Fetch(context.Background(), "foo")
+	if !errors.Is(err, new(ErrCircuitOpen)) {
+		t.Fatal("error type not expected:", reflect.TypeOf(err).String())
+	}
+	if atomic.LoadInt32(&s.attempts) != 3 {
+		t.Fatal("request was sent through the open circuit")
+	}
+
+	// Failed probe opens the circuit again.
+	s.timer.fire()
+	s.expectState(t, BreakerHalfOpen)
+	if _, err := api.Fetch(context.Background(), "foo"); errors.Is(err, new(ErrCircuitOpen)) {
+		t.Fatal("probe request was not let through")
+	}
+	s.expectState(t, BreakerOpen)
+
+	// Successful probe closes it.
+	atomic.StoreInt32(&s.statusCode, 200)
+	s.timer.fire()
+	s.expectState(t, BreakerHalfOpen)
+	if _, err := api.Fetch(context.Background(), "foo"); err != nil {
+		t.Fatal("no error expected, got:", err)
+	}
+	s.expectState(t, BreakerClosed)
+
+	if _, err := api.Fetch(context.Background(), "foo"); err != nil {
+		t.Fatal("no error expected, got:", err)
+	}
+}
+
+func TestCircuitBreakerFailureRate(t *testing.T) {
+	s := newBreakerTestState(200)
+
+	api := NewAPI(
+		WithHttpClient(s.client()),
+		WithClock(s.clock()),
+		WithRetryCount(1),
+		WithCircuitBreaker(CircuitBreakerSettings{
+			FailureRate: 0.5,
+			Window:      4,
+			OnStateChange: func(from, to BreakerState) {
+				s.states <- to
+			},
+		}),
+	)
+
+	for _, statusCode := range []int32{200, 503, 200, 404, 200, 503} {
+		atomic.StoreInt32(&s.statusCode, statusCode)
+		api.Fetch(context.Background(), "foo")
+
+		select {
+		case state := <-s.states:
+			t.Fatal("unexpected state change:", state)
+		default:
+		}
+	}
+
+	atomic.StoreInt32(&s.statusCode, 504)
+	api.Fetch(context.Background(), "foo")
+	s.expectState(t, BreakerOpen)
+}
+
+func TestCircuitBreakerThrottled(t *testing.T) {
+	s := newBreakerTestState(429)
+
+	api := NewAPI(
+		WithHttpClient(s.client()),
+		WithClock(s.clock()),
+		WithRetryCount(1),
+		WithCircuitBreaker(CircuitBreakerSettings{
+			ConsecutiveFailures: 3,
+			OnStateChange: func(from, to BreakerState) {
+				s.states <- to
+			},
+		}),
+	)
+
+	for i := 0; i < 5; i++ {
+		if _, err := api.Fetch(context.Background(), "foo"); !errors.Is(err, new(ErrTooManyRetries)) {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+	if len(s.states) != 0 {
+		t.Error("expected the circuit to stay closed, got:", <-s.states)
+	}
+}
+
+func TestCircuitBreakerIgnoresClientErrors(t *testing.T) {
+	for _, err := range []error{
+		nil,
+		new(ErrNotFound),
+		newErrConflict(GenericError{}),
+		context.Canceled,
+		new(ErrTooManyRetries),
+		&ErrTooManyRetries{StatusCode: 429, Err: newErrHttp(429)},
+	} {
+		if isOutageError(err) {
+			t.Errorf("%v: not expected to be an outage error", err)
+		}
+	}
+
+	for _, err := range []error{
+		&ErrTooManyRetries{StatusCode: 500, Err: newErrHttp(500)},
+		&ErrTooManyRetries{StatusCode: 502, Err: newErrBadGateway(GenericError{})},
+		newErrHttp(500),
+		newErrBadGateway(GenericError{}),
+		context.DeadlineExceeded,
+	} {
+		if !isOutageError(err) {
+			t.Errorf("%v: expected to be an outage error", err)
+		}
+	}
+}
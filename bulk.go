@@ -0,0 +1,164 @@
+package form3api
+
+import (
+	"context"
+	"sync"
+)
+
+const (
+	DefaultBulkConcurrency = 8
+)
+
+// BulkMode decides what happens to the remaining items of a bulk operation
+// after one of them fails.
+type BulkMode int
+
+const (
+	// Process all the items regardless of failures.
+	BestEffort BulkMode = iota
+	// Stop dispatching items after the first failure. Requests already in
+	// flight are allowed to complete.
+	StopOnError
+)
+
+// BulkOptions configures CreateMany, FetchMany and DeleteMany.
+type BulkOptions struct {
+	// Maximum number of requests in flight. Defaults to
+	// DefaultBulkConcurrency.
+	Concurrency int
+	Mode        BulkMode
+}
+
+// BulkResult is the outcome of a single item of a bulk operation.
+type BulkResult struct {
+	// Index of the item in the input slice.
+	Index int
+	// Account returned by the server. Empty for DeleteMany and on errors.
+	Data AccountData
+	Err  error
+}
+
+func runBulk(
+	ctx context.Context,
+	n int,
+	options BulkOptions,
+	do func(ctx context.Context, i int) (AccountData, error),
+	fn func(BulkResult),
+) error {
+	concurrency := options.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultBulkConcurrency
+	}
+	if concurrency > n {
+		concurrency = n
+	}
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+		indices  = make(chan int)
+		stop     = make(chan struct{})
+	)
+
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				select {
+				case <-stop:
+					// Items handed out before the first failure was seen
+					// are dropped.
+					continue
+				default:
+				}
+				data, err := do(ctx, i)
+
+				// Serialize callbacks, so that callers don't have to.
+				mu.Lock()
+				if fn != nil {
+					fn(BulkResult{Index: i, Data: data, Err: err})
+				}
+				if err != nil && firstErr == nil && options.Mode == StopOnError {
+					firstErr = err
+					close(stop)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+feed:
+	for i := 0; i < n; i++ {
+		// select picks ready cases at random, so stop has to be checked
+		// first.
+		select {
+		case <-stop:
+			break feed
+		default:
+		}
+		select {
+		case indices <- i:
+		case <-stop:
+			break feed
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(indices)
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
+
+// CreateMany creates accounts using up to options.Concurrency concurrent
+// requests. fn, if not nil, is called with the result of every processed
+// account, one call at a time. In StopOnError mode the first error is
+// returned, otherwise errors are reported only through fn.
+func CreateMany(
+	ctx context.Context,
+	api API,
+	accounts []AccountData,
+	options BulkOptions,
+	fn func(BulkResult),
+) error {
+	return runBulk(ctx, len(accounts), options, func(ctx context.Context, i int) (AccountData, error) {
+		return api.Create(ctx, accounts[i])
+	}, fn)
+}
+
+// FetchMany fetches accounts with the given IDs concurrently. See CreateMany
+// for details.
+func FetchMany(
+	ctx context.Context,
+	api API,
+	accountIDs []string,
+	options BulkOptions,
+	fn func(BulkResult),
+) error {
+	return runBulk(ctx, len(accountIDs), options, func(ctx context.Context, i int) (AccountData, error) {
+		return api.Fetch(ctx, accountIDs[i])
+	}, fn)
+}
+
+// DeleteMany deletes accounts concurrently, using their ID and Version
+// fields. See CreateMany for details.
+func DeleteMany(
+	ctx context.Context,
+	api API,
+	accounts []AccountData,
+	options BulkOptions,
+	fn func(BulkResult),
+) error {
+	return runBulk(ctx, len(accounts), options, func(ctx context.Context, i int) (AccountData, error) {
+		var version int64
+		if accounts[i].Version != nil {
+			version = *accounts[i].Version
+		}
+		return AccountData{}, api.Delete(ctx, accounts[i].ID, version)
+	}, fn)
+}
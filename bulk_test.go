@@ -0,0 +1,150 @@
+package form3api
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newClientServingAccounts returns a client that pretends that all accounts
+// exist, apart from those with IDs listed in missing.
+func newClientServingAccounts(inFlight, maxInFlight *int32, missing ...string) *http.Client {
+	return &http.Client{
+		Transport: &testRoundTripper{
+			roundTrip: func(req *http.Request) (*http.Response, error) {
+				n := atomic.AddInt32(inFlight, 1)
+				defer atomic.AddInt32(inFlight, -1)
+				for {
+					m := atomic.LoadInt32(maxInFlight)
+					if n <= m || atomic.CompareAndSwapInt32(maxInFlight, m, n) {
+						break
+					}
+				}
+				// Give other workers a chance to overlap.
+				time.Sleep(time.Millisecond)
+
+				id := path.Base(req.URL.Path)
+				for _, m := range missing {
+					if id == m {
+						return &http.Response{
+							StatusCode: 404,
+							Body:       http.NoBody,
+							Request:    req,
+						}, nil
+					}
+				}
+
+				return &http.Response{
+					StatusCode: 200,
+					Body: io.NopCloser(
+						bytes.NewBufferString(fmt.Sprintf(`{"data": {"id": %q}}`, id)),
+					),
+					Request: req,
+				}, nil
+			},
+		},
+	}
+}
+
+func TestFetchManyBestEffort(t *testing.T) {
+	var inFlight, maxInFlight int32
+
+	api := NewAPI(
+		WithHttpClient(newClientServingAccounts(&inFlight, &maxInFlight, "3", "7")),
+	)
+
+	var ids []string
+	for i := 0; i < 20; i++ {
+		ids = append(ids, fmt.Sprint(i))
+	}
+
+	var (
+		results []BulkResult
+		failed  []int
+	)
+	err := FetchMany(context.Background(), api, ids, BulkOptions{Concurrency: 4}, func(r BulkResult) {
+		results = append(results, r)
+		if r.Err != nil {
+			if !errors.Is(r.Err, new(ErrNotFound)) {
+				t.Error("error type not expected:", r.Err)
+			}
+			failed = append(failed, r.Index)
+		} else if r.Data.ID != ids[r.Index] {
+			t.Errorf("unexpected id %s at index %d", r.Data.ID, r.Index)
+		}
+	})
+	if err != nil {
+		t.Fatal("no error expected, got:", err)
+	}
+
+	if len(results) != len(ids) {
+		t.Error("unexpected number of results:", len(results))
+	}
+
+	sort.Ints(failed)
+	if len(failed) != 2 || failed[0] != 3 || failed[1] != 7 {
+		t.Error("unexpected failed items:", failed)
+	}
+
+	if m := atomic.LoadInt32(&maxInFlight); m > 4 {
+		t.Error("concurrency limit exceeded:", m)
+	}
+}
+
+func TestDeleteManyStopOnError(t *testing.T) {
+	var inFlight, maxInFlight int32
+
+	api := NewAPI(
+		WithHttpClient(newClientServingAccounts(&inFlight, &maxInFlight, "0")),
+	)
+
+	var accounts []AccountData
+	for i := 0; i < 100; i++ {
+		accounts = append(accounts, AccountData{ID: fmt.Sprint(i)})
+	}
+
+	var (
+		mu        sync.Mutex
+		processed int
+	)
+	err := DeleteMany(context.Background(), api, accounts, BulkOptions{
+		Concurrency: 1,
+		Mode:        StopOnError,
+	}, func(r BulkResult) {
+		mu.Lock()
+		processed++
+		mu.Unlock()
+	})
+	if !errors.Is(err, new(ErrNotFound)) {
+		t.Fatal("expected not found error, got:", err)
+	}
+
+	if processed != 1 {
+		t.Error("expected processing to stop after the first item, got:", processed)
+	}
+}
+
+func TestCreateManyCancelled(t *testing.T) {
+	var inFlight, maxInFlight int32
+
+	api := NewAPI(
+		WithHttpClient(newClientServingAccounts(&inFlight, &maxInFlight)),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := CreateMany(ctx, api, make([]AccountData, 10), BulkOptions{}, nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatal("expected context error, got:", err)
+	}
+}
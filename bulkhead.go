@@ -0,0 +1,110 @@
+package form3api
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// BulkheadSettings configures the concurrency limit installed with
+// WithBulkhead.
+type BulkheadSettings struct {
+	// Maximum number of requests of the operation kind in flight at once.
+	// Further requests queue until a slot frees up or their context is done.
+	MaxConcurrent uint
+
+	// Stats, if set, is updated with every request passing the bulkhead.
+	Stats *BulkheadStats
+}
+
+// BulkheadStats counts requests passing a bulkhead and the time they spent
+// queueing. It is safe for concurrent use.
+type BulkheadStats struct {
+	acquired atomic.Int64
+	rejected atomic.Int64
+	queued   atomic.Int64
+	wait     atomic.Int64
+}
+
+// Acquired returns the number of requests, which got a slot.
+func (s *BulkheadStats) Acquired() int64 {
+	return s.acquired.Load()
+}
+
+// Rejected returns the number of requests, whose context was done before they
+// got a slot.
+func (s *BulkheadStats) Rejected() int64 {
+	return s.rejected.Load()
+}
+
+// Queued returns the number of requests currently waiting for a slot.
+func (s *BulkheadStats) Queued() int64 {
+	return s.queued.Load()
+}
+
+// TotalWait returns the time all requests spent waiting for a slot.
+func (s *BulkheadStats) TotalWait() time.Duration {
+	return time.Duration(s.wait.Load())
+}
+
+type bulkhead struct {
+	slots chan struct{}
+	stats *BulkheadStats
+}
+
+func newBulkhead(settings BulkheadSettings) *bulkhead {
+	if settings.MaxConcurrent == 0 {
+		settings.MaxConcurrent = 1
+	}
+	if settings.Stats == nil {
+		settings.Stats = new(BulkheadStats)
+	}
+	return &bulkhead{
+		slots: make(chan struct{}, settings.MaxConcurrent),
+		stats: settings.Stats,
+	}
+}
+
+// acquire blocks until a slot is free, or ctx is done. Acquired slot has to be
+// given back with release.
+func (b *bulkhead) acquire(ctx context.Context, clock Clock) error {
+	select {
+	case b.slots <- struct{}{}:
+		b.stats.acquired.Add(1)
+		return nil
+	default:
+	}
+
+	b.stats.queued.Add(1)
+	defer b.stats.queued.Add(-1)
+
+	start := clock.Now()
+	defer func() {
+		b.stats.wait.Add(int64(clock.Now().Sub(start)))
+	}()
+
+	select {
+	case b.slots <- struct{}{}:
+		b.stats.acquired.Add(1)
+		return nil
+	case <-ctx.Done():
+		b.stats.rejected.Add(1)
+		return ctx.Err()
+	}
+}
+
+func (b *bulkhead) release() {
+	<-b.slots
+}
+
+// WithBulkhead limits the number of concurrent requests of the op kind, so
+// that, for ex. bulk Create calls cannot starve latency-critical Fetch calls
+// of connections.
+func WithBulkhead(op Operation, settings BulkheadSettings) func(*api) {
+	return func(a *api) {
+		if a.bulkheads == nil {
+			a.bulkheads = make(map[Operation]*bulkhead)
+		}
+		a.bulkheads[op] = newBulkhead(settings)
+	}
+}
@@ -0,0 +1,108 @@
+package form3api
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestBulkheadAcquire(t *testing.T) {
+	clock := newTestClock()
+	stats := new(BulkheadStats)
+	b := newBulkhead(BulkheadSettings{MaxConcurrent: 1, Stats: stats})
+
+	if err := b.acquire(context.Background(), clock); err != nil {
+		t.Fatal("no error expected, got:", err)
+	}
+
+	acquired := make(chan error)
+	go func() {
+		acquired <- b.acquire(context.Background(), clock)
+	}()
+
+	for stats.Queued() != 1 {
+		time.Sleep(time.Millisecond)
+	}
+	clock.advance(time.Second)
+	b.release()
+
+	if err := <-acquired; err != nil {
+		t.Fatal("no error expected, got:", err)
+	}
+	if stats.Acquired() != 2 || stats.Queued() != 0 {
+		t.Errorf("unexpected stats: acquired %d, queued %d", stats.Acquired(), stats.Queued())
+	}
+	if stats.TotalWait() != time.Second {
+		t.Error("unexpected wait time:", stats.TotalWait())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := b.acquire(ctx, clock); !errors.Is(err, context.Canceled) {
+		t.Error("expected context canceled error, got:", err)
+	}
+	if stats.Rejected() != 1 {
+		t.Error("unexpected rejected count:", stats.Rejected())
+	}
+}
+
+func TestApiBulkheadPerOperation(t *testing.T) {
+	var (
+		started = make(chan struct{})
+		unblock = make(chan struct{})
+	)
+	client := &http.Client{
+		Transport: &testRoundTripper{
+			roundTrip: func(req *http.Request) (*http.Response, error) {
+				if req.Method == http.MethodPost {
+					started <- struct{}{}
+					<-unblock
+				}
+				return &http.Response{
+					StatusCode: 201,
+					Body:       io.NopCloser(bytes.NewBufferString(testAccountMessage)),
+					Request:    req,
+				}, nil
+			},
+		},
+	}
+
+	stats := new(BulkheadStats)
+	api := NewAPI(
+		WithHttpClient(client),
+		WithBulkhead(OperationCreate, BulkheadSettings{MaxConcurrent: 1, Stats: stats}),
+	)
+
+	created := make(chan error)
+	go func() {
+		_, err := api.Create(context.Background(), AccountData{})
+		created <- err
+	}()
+	<-started
+
+	// Another Create has to queue until the deadline.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if _, err := api.Create(ctx, AccountData{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Error("expected deadline exceeded error, got:", err)
+	}
+	if stats.Rejected() != 1 {
+		t.Error("unexpected rejected count:", stats.Rejected())
+	}
+
+	// Fetch isn't limited by the Create bulkhead.
+	if _, err := api.Fetch(context.Background(), "foo"); err != nil {
+		t.Error("no error expected, got:", err)
+	}
+
+	close(unblock)
+	if err := <-created; err != nil {
+		t.Error("no error expected, got:", err)
+	}
+}
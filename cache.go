@@ -0,0 +1,325 @@
+package form3api
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultCacheTTL        = time.Minute
+	DefaultCacheMaxEntries = 1024
+)
+
+// CacheSettings configures the cache created with NewCachingAPI. Zero values
+// are replaced with defaults.
+type CacheSettings struct {
+	// How long a fetched account is served from the cache before it gets
+	// revalidated with the server.
+	TTL time.Duration
+	// Maximum number of cached accounts. Least recently used accounts are
+	// evicted first.
+	MaxEntries int
+	// Source of time for expiring cached accounts. Defaults to the clock of
+	// the wrapped API, when created with NewAPI, or SystemClock.
+	Clock Clock
+}
+
+// conditionalFetcher is implemented by API instances able to revalidate
+// cached accounts using entity tags.
+type conditionalFetcher interface {
+	fetchIfNoneMatch(ctx context.Context, accountID, etag string) (AccountData, string, bool, error)
+}
+
+type cacheEntry struct {
+	accountID string
+	data      AccountData
+	etag      string
+	version   int64
+	expires   time.Time
+	// Tombstones are left behind by accounts we've deleted. They are never
+	// served, but keep representations older than the deletion out of the
+	// cache until they expire.
+	tombstone bool
+}
+
+type cachingAPI struct {
+	next     API
+	settings CacheSettings
+
+	mu      sync.Mutex
+	lru     *list.List
+	entries map[string]*list.Element
+}
+
+func accountVersion(data AccountData) int64 {
+	if data.Version == nil {
+		return -1
+	}
+	return *data.Version
+}
+
+// Must be called with c.mu held.
+func (c *cachingAPI) get(accountID string) *cacheEntry {
+	el, ok := c.entries[accountID]
+	if !ok {
+		return nil
+	}
+
+	e := el.Value.(*cacheEntry)
+	if e.tombstone && !c.settings.Clock.Now().Before(e.expires) {
+		c.lru.Remove(el)
+		delete(c.entries, accountID)
+		return nil
+	}
+	c.lru.MoveToFront(el)
+	return e
+}
+
+// Must be called with c.mu held.
+func (c *cachingAPI) set(e *cacheEntry) {
+	if el, ok := c.entries[e.accountID]; ok {
+		el.Value = e
+		c.lru.MoveToFront(el)
+		return
+	}
+
+	c.entries[e.accountID] = c.lru.PushFront(e)
+	for c.lru.Len() > c.settings.MaxEntries {
+		el := c.lru.Back()
+		c.lru.Remove(el)
+		delete(c.entries, el.Value.(*cacheEntry).accountID)
+	}
+}
+
+func (c *cachingAPI) put(data AccountData, etag string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.store(data, etag)
+}
+
+// created caches an account we've just created. It replaces the tombstone of
+// a deleted account with the same ID, as the server has accepted the new one.
+func (c *cachingAPI) created(data AccountData) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[data.ID]; ok && el.Value.(*cacheEntry).tombstone {
+		c.lru.Remove(el)
+		delete(c.entries, data.ID)
+	}
+	c.store(data, "")
+}
+
+// Must be called with c.mu held.
+func (c *cachingAPI) store(data AccountData, etag string) {
+	version := accountVersion(data)
+	if e := c.get(data.ID); e != nil && e.version > version {
+		// We already know about a newer version.
+		return
+	}
+
+	c.set(&cacheEntry{
+		accountID: data.ID,
+		data:      data.clone(),
+		etag:      etag,
+		version:   version,
+		expires:   c.settings.Clock.Now().Add(c.settings.TTL),
+	})
+}
+
+// remove drops the cached account. Tombstones stay in place.
+func (c *cachingAPI) remove(accountID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[accountID]; ok && !el.Value.(*cacheEntry).tombstone {
+		c.lru.Remove(el)
+		delete(c.entries, accountID)
+	}
+}
+
+// lookup returns a fresh cached account, or the entity tag of a stale one.
+func (c *cachingAPI) lookup(accountID string) (AccountData, string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e := c.get(accountID)
+	switch {
+	case e == nil, e.tombstone:
+		return AccountData{}, "", false
+	case c.settings.Clock.Now().Before(e.expires):
+		return e.data.clone(), "", true
+	default:
+		return AccountData{}, e.etag, false
+	}
+}
+
+// refresh extends the lifetime of the cached account, if it still has the
+// given entity tag.
+func (c *cachingAPI) refresh(accountID, etag string) (AccountData, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e := c.get(accountID)
+	if e == nil || e.tombstone || e.etag != etag {
+		return AccountData{}, false
+	}
+	e.expires = c.settings.Clock.Now().Add(c.settings.TTL)
+	return e.data.clone(), true
+}
+
+func (c *cachingAPI) fetch(ctx context.Context, accountID, etag string) (AccountData, error) {
+	f, ok := c.next.(conditionalFetcher)
+	if !ok {
+		data, err := c.next.Fetch(ctx, accountID)
+		if err != nil {
+			return AccountData{}, err
+		}
+		c.put(data, "")
+		return data, nil
+	}
+
+	data, newETag, notModified, err := f.fetchIfNoneMatch(ctx, accountID, etag)
+	if err != nil {
+		return AccountData{}, err
+	}
+
+	if notModified {
+		if data, ok := c.refresh(accountID, etag); ok {
+			return data, nil
+		}
+		// Cached account has been invalidated in the meantime.
+		return c.fetch(ctx, accountID, "")
+	}
+
+	c.put(data, newETag)
+	return data, nil
+}
+
+func (c *cachingAPI) Create(ctx context.Context, data AccountData) (AccountData, error) {
+	ret, err := c.next.Create(ctx, data)
+	if err != nil {
+		return AccountData{}, err
+	}
+	c.created(ret)
+	return ret, nil
+}
+
+func (c *cachingAPI) CreateDocument(ctx context.Context, data AccountData) (Document[AccountData], error) {
+	d, ok := c.next.(DocumentAPI)
+	if !ok {
+		ret, err := c.Create(ctx, data)
+		return Document[AccountData]{Data: ret}, err
+	}
+
+	ret, err := d.CreateDocument(ctx, data)
+	if err != nil {
+		return Document[AccountData]{}, err
+	}
+	c.created(ret.Data)
+	return ret, nil
+}
+
+func (c *cachingAPI) Fetch(ctx context.Context, accountID string) (AccountData, error) {
+	data, etag, ok := c.lookup(accountID)
+	if ok {
+		return data, nil
+	}
+
+	data, err := c.fetch(ctx, accountID, etag)
+	if err != nil {
+		var notFound *ErrNotFound
+		if errors.As(err, &notFound) {
+			c.remove(accountID)
+		}
+		return AccountData{}, err
+	}
+	return data, nil
+}
+
+// FetchDocument always asks the wrapped API, as links and meta aren't cached.
+func (c *cachingAPI) FetchDocument(ctx context.Context, accountID string) (Document[AccountData], error) {
+	d, ok := c.next.(DocumentAPI)
+	if !ok {
+		ret, err := c.fetch(ctx, accountID, "")
+		if err != nil {
+			var notFound *ErrNotFound
+			if errors.As(err, &notFound) {
+				c.remove(accountID)
+			}
+		}
+		return Document[AccountData]{Data: ret}, err
+	}
+
+	ret, err := d.FetchDocument(ctx, accountID)
+	if err != nil {
+		var notFound *ErrNotFound
+		if errors.As(err, &notFound) {
+			c.remove(accountID)
+		}
+		return Document[AccountData]{}, err
+	}
+	c.put(ret.Data, "")
+	return ret, nil
+}
+
+func (c *cachingAPI) List(ctx context.Context, options ListOptions, fn func(AccountData) error) error {
+	return c.next.List(ctx, options, fn)
+}
+
+func (c *cachingAPI) ListPage(ctx context.Context, options ListOptions, fn func(AccountData) error) (Page, error) {
+	d, ok := c.next.(DocumentAPI)
+	if !ok {
+		return Page{}, c.next.List(ctx, options, fn)
+	}
+	return d.ListPage(ctx, options, fn)
+}
+
+func (c *cachingAPI) Health(ctx context.Context) (HealthStatus, error) {
+	return c.next.Health(ctx)
+}
+
+func (c *cachingAPI) Delete(ctx context.Context, accountID string, version int64) error {
+	if err := c.next.Delete(ctx, accountID, version); err != nil {
+		// Whatever we have cached is most likely out of date.
+		c.remove(accountID)
+		return err
+	}
+
+	c.mu.Lock()
+	c.set(&cacheEntry{
+		accountID: accountID,
+		version:   version + 1,
+		expires:   c.settings.Clock.Now().Add(c.settings.TTL),
+		tombstone: true,
+	})
+	c.mu.Unlock()
+	return nil
+}
+
+// NewCachingAPI wraps next with a read-through cache of fetched accounts.
+// Accounts created or deleted through the returned API are reflected in the
+// cache immediately, and representations older than the version we deleted
+// aren't cached again for TTL. When the server supplies entity tags, expired
+// accounts are revalidated with conditional requests.
+func NewCachingAPI(next API, settings CacheSettings) API {
+	if settings.TTL <= 0 {
+		settings.TTL = DefaultCacheTTL
+	}
+	if settings.MaxEntries <= 0 {
+		settings.MaxEntries = DefaultCacheMaxEntries
+	}
+	if settings.Clock == nil {
+		settings.Clock = clockOf(next)
+	}
+
+	return &cachingAPI{
+		next:     next,
+		settings: settings,
+		lru:      list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
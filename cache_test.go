@@ -0,0 +1,275 @@
+package form3api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testAccountServer serves accounts with versions and entity tags.
+type testAccountServer struct {
+	mu          sync.Mutex
+	versions    map[string]int64
+	fetches     int
+	notModified int
+}
+
+func newTestAccountServer() *testAccountServer {
+	return &testAccountServer{
+		versions: make(map[string]int64),
+	}
+}
+
+func (s *testAccountServer) client() *http.Client {
+	return &http.Client{
+		Transport: &testRoundTripper{
+			roundTrip: func(req *http.Request) (*http.Response, error) {
+				s.mu.Lock()
+				defer s.mu.Unlock()
+
+				id := path.Base(req.URL.Path)
+				version, ok := s.versions[id]
+
+				switch {
+				case req.Method == http.MethodPost:
+					var doc struct{ Data AccountData }
+					if err := json.NewDecoder(req.Body).Decode(&doc); err != nil {
+						return nil, err
+					}
+					s.versions[doc.Data.ID] = 0
+					return &http.Response{
+						StatusCode: 201,
+						Body: io.NopCloser(bytes.NewBufferString(
+							fmt.Sprintf(`{"data": {"id": %q, "version": 0}}`, doc.Data.ID),
+						)),
+						Request: req,
+					}, nil
+				case req.Method == http.MethodDelete:
+					delete(s.versions, id)
+					return &http.Response{StatusCode: 204, Body: http.NoBody, Request: req}, nil
+				case !ok:
+					return &http.Response{StatusCode: 404, Body: http.NoBody, Request: req}, nil
+				}
+
+				s.fetches++
+				etag := fmt.Sprintf(`"%s-%d"`, id, version)
+				if req.Header.Get("If-None-Match") == etag {
+					s.notModified++
+					return &http.Response{StatusCode: 304, Body: http.NoBody, Request: req}, nil
+				}
+
+				return &http.Response{
+					StatusCode: 200,
+					Header:     http.Header{"Etag": []string{etag}},
+					Body: io.NopCloser(bytes.NewBufferString(
+						fmt.Sprintf(`{"data": {"id": %q, "version": %d}}`, id, version),
+					)),
+					Request: req,
+				}, nil
+			},
+		},
+	}
+}
+
+func (s *testAccountServer) counters() (int, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.fetches, s.notModified
+}
+
+func newTestCachingAPI(s *testAccountServer, settings CacheSettings) (*cachingAPI, *testClock) {
+	clock := newTestClock()
+	api := NewAPI(WithHttpClient(s.client()), WithClock(clock))
+	return NewCachingAPI(api, settings).(*cachingAPI), clock
+}
+
+func TestCachingAPIFetchRevalidation(t *testing.T) {
+	s := newTestAccountServer()
+	s.versions["foo"] = 0
+
+	c, clock := newTestCachingAPI(s, CacheSettings{TTL: time.Minute})
+
+	for i := 0; i < 3; i++ {
+		data, err := c.Fetch(context.Background(), "foo")
+		if err != nil {
+			t.Fatal("no error expected, got:", err)
+		}
+		if data.ID != "foo" {
+			t.Fatal("unexpected id:", data.ID)
+		}
+	}
+	if fetches, _ := s.counters(); fetches != 1 {
+		t.Fatal("expected a single request, got:", fetches)
+	}
+
+	clock.advance(2 * time.Minute)
+	if _, err := c.Fetch(context.Background(), "foo"); err != nil {
+		t.Fatal("no error expected, got:", err)
+	}
+	if _, notModified := s.counters(); notModified != 1 {
+		t.Fatal("expected conditional request, got:", notModified)
+	}
+
+	// Revalidated entry is fresh again.
+	if _, err := c.Fetch(context.Background(), "foo"); err != nil {
+		t.Fatal("no error expected, got:", err)
+	}
+	if fetches, _ := s.counters(); fetches != 2 {
+		t.Fatal("unexpected number of requests:", fetches)
+	}
+}
+
+func TestCachingAPIDeleteInvalidation(t *testing.T) {
+	s := newTestAccountServer()
+	s.versions["foo"] = 3
+
+	c, _ := newTestCachingAPI(s, CacheSettings{})
+
+	data, err := c.Fetch(context.Background(), "foo")
+	if err != nil {
+		t.Fatal("no error expected, got:", err)
+	}
+
+	if err := c.Delete(context.Background(), "foo", *data.Version); err != nil {
+		t.Fatal("no error expected, got:", err)
+	}
+
+	if _, err := c.Fetch(context.Background(), "foo"); !errors.Is(err, new(ErrNotFound)) {
+		t.Fatal("expected not found error, got:", err)
+	}
+
+	// A stale representation fetched before the deletion must not be cached.
+	c.put(data, "")
+	if _, _, ok := c.lookup("foo"); ok {
+		t.Error("stale account was cached")
+	}
+
+	s.versions["foo"] = 4
+	if _, err := c.Fetch(context.Background(), "foo"); err != nil {
+		t.Fatal("no error expected, got:", err)
+	}
+	if _, _, ok := c.lookup("foo"); !ok {
+		t.Error("expected newer account to be cached")
+	}
+}
+
+func TestCachingAPIRecreate(t *testing.T) {
+	s := newTestAccountServer()
+	s.versions["a1"] = 3
+
+	c, _ := newTestCachingAPI(s, CacheSettings{})
+
+	if err := c.Delete(context.Background(), "a1", 3); err != nil {
+		t.Fatal("no error expected, got:", err)
+	}
+	if _, err := c.Create(context.Background(), AccountData{ID: "a1"}); err != nil {
+		t.Fatal("no error expected, got:", err)
+	}
+
+	for i := 0; i < 5; i++ {
+		if _, err := c.Fetch(context.Background(), "a1"); err != nil {
+			t.Fatal("no error expected, got:", err)
+		}
+	}
+	if fetches, _ := s.counters(); fetches != 0 {
+		t.Error("expected re-created account to be cached, got requests:", fetches)
+	}
+}
+
+func TestCachingAPITombstoneExpiry(t *testing.T) {
+	s := newTestAccountServer()
+
+	c, clock := newTestCachingAPI(s, CacheSettings{TTL: time.Minute})
+
+	if err := c.Delete(context.Background(), "foo", 3); err != nil {
+		t.Fatal("no error expected, got:", err)
+	}
+
+	stale := AccountData{ID: "foo", Version: Int64(1)}
+	c.put(stale, "")
+	if _, _, ok := c.lookup("foo"); ok {
+		t.Fatal("stale account was cached")
+	}
+
+	clock.advance(2 * time.Minute)
+	c.put(stale, "")
+	if _, _, ok := c.lookup("foo"); !ok {
+		t.Error("expected the tombstone to expire")
+	}
+}
+
+func TestCachingAPIEviction(t *testing.T) {
+	s := newTestAccountServer()
+	for _, id := range []string{"a", "b", "c"} {
+		s.versions[id] = 0
+	}
+
+	c, _ := newTestCachingAPI(s, CacheSettings{MaxEntries: 2})
+
+	for _, id := range []string{"a", "b", "a", "c"} {
+		if _, err := c.Fetch(context.Background(), id); err != nil {
+			t.Fatal("no error expected, got:", err)
+		}
+	}
+
+	for id, expected := range map[string]bool{"a": true, "b": false, "c": true} {
+		if _, _, ok := c.lookup(id); ok != expected {
+			t.Errorf("%s: expected cached to be %v", id, expected)
+		}
+	}
+}
+
+// plainAPI hides the methods of the wrapped API, that aren't part of API.
+type plainAPI struct {
+	API
+}
+
+func TestCachingAPIDocumentFallback(t *testing.T) {
+	s := newTestAccountServer()
+	s.versions["foo"] = 1
+
+	api := NewAPI(WithHttpClient(s.client()), WithClock(newTestClock()))
+	c := NewCachingAPI(plainAPI{api}, CacheSettings{}).(DocumentAPI)
+
+	doc, err := c.FetchDocument(context.Background(), "foo")
+	if err != nil {
+		t.Fatal("no error expected, got:", err)
+	}
+	if doc.Data.ID != "foo" || doc.Links != nil {
+		t.Fatal("unexpected document:", doc)
+	}
+
+	// Fetched account is cached.
+	if _, err := c.(API).Fetch(context.Background(), "foo"); err != nil {
+		t.Fatal("no error expected, got:", err)
+	}
+	if fetches, _ := s.counters(); fetches != 1 {
+		t.Fatal("expected a single request, got:", fetches)
+	}
+
+	_, err = c.FetchDocument(context.Background(), "bar")
+	if !errors.Is(err, new(ErrNotFound)) {
+		t.Fatal("expected not found, got:", err)
+	}
+}
+
+func TestDocumentAPIImplementations(t *testing.T) {
+	api := NewAPI()
+	for name, client := range map[string]API{
+		"api":          api,
+		"caching":      NewCachingAPI(api, CacheSettings{}),
+		"organisation": NewMultiTenantAPI().ForOrganisation("foo"),
+	} {
+		if _, ok := client.(DocumentAPI); !ok {
+			t.Errorf("%s: expected DocumentAPI to be implemented", name)
+		}
+	}
+}
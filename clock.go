@@ -0,0 +1,76 @@
+package form3api
+
+import (
+	"time"
+)
+
+// Timer is a single event timer, as created by Clock.NewTimer.
+type Timer interface {
+	// Tick returns the channel on which the time is delivered, once the timer
+	// fires.
+	Tick() <-chan time.Time
+	// Stop prevents the timer from firing, see time.Timer.Stop.
+	Stop() bool
+}
+
+// Clock is the source of time used by an API instance for back-off delays,
+// rate limiting, circuit breaking and caching. It can be replaced with
+// WithClock, for ex. with a fake clock from form3apitest package, to control
+// time in tests.
+type Clock interface {
+	Now() time.Time
+	NewTimer(d time.Duration) Timer
+	After(d time.Duration) <-chan time.Time
+}
+
+type timeTimer struct {
+	*time.Timer
+}
+
+func (t *timeTimer) Tick() <-chan time.Time {
+	return t.Timer.C
+}
+
+func (t *timeTimer) Stop() bool {
+	return t.Timer.Stop()
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) NewTimer(d time.Duration) Timer {
+	return &timeTimer{
+		Timer: time.NewTimer(d),
+	}
+}
+
+func (systemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// SystemClock is the Clock backed by the time package. It is used by
+// default.
+var SystemClock Clock = systemClock{}
+
+// clockOf returns the clock used by an API instance created with NewAPI, or
+// SystemClock for other implementations.
+func clockOf(client API) Clock {
+	switch a := client.(type) {
+	case *api:
+		return a.clock
+	case *organisationAPI:
+		return a.api.clock
+	default:
+		return SystemClock
+	}
+}
+
+// WithClock replaces the source of time used by an API instance.
+func WithClock(clock Clock) func(*api) {
+	return func(a *api) {
+		a.clock = clock
+	}
+}
@@ -0,0 +1,106 @@
+package form3api
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Content codings we are able to decode. Brotli is not supported by the
+// standard library, so we don't ask for it.
+const acceptEncoding = "gzip, deflate"
+
+// decodedBody replaces http.Response body with its decompressed form, while
+// still taking care of the original body when closed.
+type decodedBody struct {
+	io.Reader
+	decoder io.Closer
+	body    io.ReadCloser
+}
+
+// Maximum number of bytes of the original body discarded on close. Longer
+// bodies are closed without reading them till the end, so that the response
+// size limit isn't bypassed, at the cost of the connection.
+const maxDrainSize = 64 << 10
+
+func (b *decodedBody) Close() error {
+	b.decoder.Close()
+	// Needed for keepalive connection reusage.
+	io.CopyN(io.Discard, b.body, maxDrainSize)
+	return b.body.Close()
+}
+
+func isZlibHeader(h []byte) bool {
+	// See RFC 1950, section 2.2.
+	return len(h) == 2 && h[0]&0x0f == 8 && (uint16(h[0])<<8|uint16(h[1]))%31 == 0
+}
+
+func newDeflateReader(r io.Reader) (io.ReadCloser, error) {
+	// "deflate" content coding should be zlib wrapped, but quite a few servers
+	// send raw deflate streams.
+	br := bufio.NewReader(r)
+	if h, err := br.Peek(2); err == nil && isZlibHeader(h) {
+		return zlib.NewReader(br)
+	}
+	return flate.NewReader(br), nil
+}
+
+// decodeResponseBody transparently decompresses resp body according to its
+// Content-Encoding header. We have to do it ourselves, because setting
+// Accept-Encoding explicitly disables decompression done by http.Transport.
+func decodeResponseBody(resp *http.Response) error {
+	encoding := strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding")))
+
+	var (
+		r   io.ReadCloser
+		err error
+	)
+	switch encoding {
+	case "", "identity":
+		return nil
+	case "gzip", "x-gzip":
+		r, err = gzip.NewReader(resp.Body)
+	case "deflate":
+		r, err = newDeflateReader(resp.Body)
+	default:
+		return fmt.Errorf("unsupported content encoding: %s", encoding)
+	}
+
+	switch err {
+	case nil:
+	case io.EOF:
+		// Empty body, nothing to decompress.
+		return nil
+	default:
+		return err
+	}
+
+	resp.Body = &decodedBody{
+		Reader:  r,
+		decoder: r,
+		body:    resp.Body,
+	}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	return nil
+}
+
+func gzipBytes(b []byte) (*bytes.Buffer, error) {
+	var ret bytes.Buffer
+	w := gzip.NewWriter(&ret)
+	if _, err := w.Write(b); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return &ret, nil
+}
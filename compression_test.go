@@ -0,0 +1,193 @@
+package form3api
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func newClientReturningEncodedBuffer(statusCode int, encoding string, rc io.ReadCloser) *http.Client {
+	return &http.Client{
+		Transport: &testRoundTripper{
+			roundTrip: func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: statusCode,
+					Header: http.Header{
+						"Content-Encoding": []string{encoding},
+					},
+					Body:          rc,
+					ContentLength: -1,
+					Request:       req,
+				}, nil
+			},
+		},
+	}
+}
+
+func compressString(t *testing.T, encoding, s string) *bytes.Buffer {
+	var (
+		buf bytes.Buffer
+		w   io.WriteCloser
+	)
+	switch encoding {
+	case "gzip":
+		w = gzip.NewWriter(&buf)
+	case "deflate":
+		w = zlib.NewWriter(&buf)
+	case "raw-deflate":
+		w, _ = flate.NewWriter(&buf, flate.DefaultCompression)
+	}
+	if _, err := io.WriteString(w, s); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return &buf
+}
+
+func TestApiFetchCompressedResponse(t *testing.T) {
+	for _, test := range []struct {
+		encoding string
+		header   string
+	}{
+		{encoding: "gzip", header: "gzip"},
+		{encoding: "deflate", header: "deflate"},
+		{encoding: "raw-deflate", header: "deflate"},
+	} {
+		buf := newBufferCloseWrapper(compressString(t, test.encoding, testAccountMessage))
+
+		api := NewAPI(
+			WithHttpClient(
+				newClientReturningEncodedBuffer(200, test.header, buf),
+			),
+		)
+
+		data, err := api.Fetch(context.Background(), "0d209d7f-d07a-4542-947f-5885fddddae2")
+		if err != nil {
+			t.Errorf("%s: no error expected, got: %s", test.encoding, err)
+			continue
+		}
+
+		if data.ID != "0d209d7f-d07a-4542-947f-5885fddddae2" {
+			t.Errorf("%s: unexpected id: %s", test.encoding, data.ID)
+		}
+
+		if !buf.isDrainedAndClosed() {
+			t.Errorf("%s: buffer was not drained and closed", test.encoding)
+		}
+	}
+}
+
+func TestApiCompressedErrorResponse(t *testing.T) {
+	const message = `{"error_message": "invalid version"}`
+
+	api := NewAPI(
+		WithClock(newTestClock()),
+		WithHttpClient(
+			newClientReturningEncodedBuffer(
+				409,
+				"gzip",
+				newBufferCloseWrapper(compressString(t, "gzip", message)),
+			),
+		),
+	)
+
+	err := api.Delete(context.Background(), "foo", 0)
+	if !IsVersionMismatch(err) {
+		t.Error("expected version mismatch error, got:", err)
+	}
+}
+
+func TestApiUnsupportedContentEncoding(t *testing.T) {
+	buf := newBufferCloseWrapper(bytes.NewBufferString("foo"))
+
+	api := NewAPI(
+		WithHttpClient(
+			newClientReturningEncodedBuffer(200, "br", buf),
+		),
+	)
+
+	if _, err := api.Fetch(context.Background(), "foo"); err == nil {
+		t.Error("expected an error")
+	}
+
+	if !buf.isDrainedAndClosed() {
+		t.Error("buffer was not drained and closed")
+	}
+}
+
+func newClientCapturingRequestBody(body *bytes.Buffer, header *http.Header) *http.Client {
+	return &http.Client{
+		Transport: &testRoundTripper{
+			roundTrip: func(req *http.Request) (*http.Response, error) {
+				*header = req.Header.Clone()
+				if req.Body != nil {
+					io.Copy(body, req.Body)
+				}
+				return &http.Response{
+					StatusCode: 201,
+					Body:       io.NopCloser(bytes.NewBufferString(testAccountMessage)),
+					Request:    req,
+				}, nil
+			},
+		},
+	}
+}
+
+func TestApiCreateRequestCompression(t *testing.T) {
+	data := AccountData{
+		ID:   "0d209d7f-d07a-4542-947f-5885fddddae2",
+		Type: "accounts",
+	}
+
+	for _, test := range []struct {
+		minSize    int
+		compressed bool
+	}{
+		{minSize: 0, compressed: true},
+		{minSize: 1 << 20, compressed: false},
+	} {
+		var (
+			body   bytes.Buffer
+			header http.Header
+		)
+
+		api := NewAPI(
+			WithHttpClient(newClientCapturingRequestBody(&body, &header)),
+			WithRequestCompression(test.minSize),
+		)
+
+		if _, err := api.Create(context.Background(), data); err != nil {
+			t.Fatal("no error expected, got:", err)
+		}
+
+		var r io.Reader = &body
+		if test.compressed {
+			if header.Get("Content-Encoding") != "gzip" {
+				t.Fatal("expected gzip content encoding")
+			}
+			z, err := gzip.NewReader(r)
+			if err != nil {
+				t.Fatal("expected gzip body, got:", err)
+			}
+			r = z
+		} else if header.Get("Content-Encoding") != "" {
+			t.Error("unexpected content encoding:", header.Get("Content-Encoding"))
+		}
+
+		var sent struct{ Data AccountData }
+		if err := json.NewDecoder(r).Decode(&sent); err != nil {
+			t.Fatal("could not decode request body:", err)
+		}
+		if sent.Data.ID != data.ID {
+			t.Error("unexpected id:", sent.Data.ID)
+		}
+	}
+}
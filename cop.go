@@ -0,0 +1,163 @@
+package form3api
+
+import (
+	"context"
+	"strings"
+)
+
+// ConfirmationOfPayeeAPI checks account holder names before accounts are
+// created or paid into.
+// See https://www.api-docs.form3.tech/api/schemes/confirmation-of-payee
+type ConfirmationOfPayeeAPI interface {
+	// Confirm checks whether the account identified by BankID, AccountNumber
+	// and SecondaryIdentification attributes is held by the party named in
+	// the Name attribute.
+	Confirm(ctx context.Context, account AccountAttributes) (PayeeMatch, error)
+
+	// Identify looks up details of the account identified either by Iban, or
+	// BankID and AccountNumber attributes, including the holder name.
+	Identify(ctx context.Context, account AccountAttributes) (AccountAttributes, error)
+}
+
+// MatchResult tells how the checked name matches the account holder name.
+type MatchResult int
+
+const (
+	// Name doesn't match, or the account couldn't be checked.
+	MatchNone MatchResult = iota
+	// Name matches closely, the actual name is suggested.
+	MatchClose
+	// Name matches exactly.
+	MatchExact
+)
+
+func (r MatchResult) String() string {
+	switch r {
+	case MatchNone:
+		return "none"
+	case MatchClose:
+		return "close"
+	case MatchExact:
+		return "exact"
+	default:
+		return "unknown"
+	}
+}
+
+// Confirmation of Payee reason codes returned with close or no matches.
+const (
+	ReasonNameNoMatch          = "ANNM"
+	ReasonMayBeMatch           = "MBAM"
+	ReasonBusinessAccountMatch = "BANM"
+	ReasonPersonalAccountMatch = "PANM"
+	ReasonAccountNotFound      = "AC01"
+	ReasonAccountNotSupported  = "ACNS"
+	ReasonOptedOut             = "OPTO"
+	ReasonAccountSwitched      = "CASS"
+)
+
+// PayeeMatch is the result of a Confirmation of Payee check.
+type PayeeMatch struct {
+	Result MatchResult
+	// Name of the account holder, when the match is close.
+	SuggestedName string
+	// Why the match isn't exact, see Reason constants.
+	ReasonCode string
+}
+
+type payeeCheck struct {
+	ID         string               `json:"id,omitempty"`
+	Type       string               `json:"type"`
+	Attributes payeeCheckAttributes `json:"attributes"`
+}
+
+type payeeCheckAttributes struct {
+	AccountClassification   *string `json:"account_classification,omitempty"`
+	AccountNumber           string  `json:"account_number,omitempty"`
+	BankID                  string  `json:"bank_id,omitempty"`
+	BankIDCode              string  `json:"bank_id_code,omitempty"`
+	Name                    string  `json:"name,omitempty"`
+	SecondaryIdentification string  `json:"secondary_identification,omitempty"`
+
+	// Set in responses only.
+	Matched    *bool  `json:"matched,omitempty"`
+	ActualName string `json:"actual_name,omitempty"`
+	ReasonCode string `json:"reason_code,omitempty"`
+}
+
+func (a payeeCheckAttributes) match() PayeeMatch {
+	switch {
+	case a.Matched != nil && *a.Matched:
+		return PayeeMatch{Result: MatchExact}
+	case a.ReasonCode == ReasonMayBeMatch,
+		a.ReasonCode == ReasonBusinessAccountMatch,
+		a.ReasonCode == ReasonPersonalAccountMatch:
+		return PayeeMatch{
+			Result:        MatchClose,
+			SuggestedName: a.ActualName,
+			ReasonCode:    a.ReasonCode,
+		}
+	default:
+		return PayeeMatch{
+			Result:     MatchNone,
+			ReasonCode: a.ReasonCode,
+		}
+	}
+}
+
+type accountIdentification struct {
+	ID         string            `json:"id,omitempty"`
+	Type       string            `json:"type"`
+	Attributes AccountAttributes `json:"attributes"`
+}
+
+type confirmationOfPayeeAPI struct {
+	api *api
+}
+
+// NewConfirmationOfPayeeAPI creates a Confirmation of Payee client. It accepts
+// the same options as NewAPI.
+func NewConfirmationOfPayeeAPI(options ...func(*api)) ConfirmationOfPayeeAPI {
+	return &confirmationOfPayeeAPI{
+		api: newAPI(options...),
+	}
+}
+
+func (c *confirmationOfPayeeAPI) Confirm(ctx context.Context, account AccountAttributes) (PayeeMatch, error) {
+	doc, err := createDocument(
+		ctx,
+		c.api,
+		BaseURL+"/v1/confirmation-of-payee",
+		payeeCheck{
+			Type: "confirmation_of_payee",
+			Attributes: payeeCheckAttributes{
+				AccountClassification:   account.AccountClassification,
+				AccountNumber:           account.AccountNumber,
+				BankID:                  account.BankID,
+				BankIDCode:              account.BankIDCode,
+				Name:                    strings.Join(account.Name, " "),
+				SecondaryIdentification: account.SecondaryIdentification,
+			},
+		},
+	)
+	if err != nil {
+		return PayeeMatch{}, err
+	}
+	return doc.Data.Attributes.match(), nil
+}
+
+func (c *confirmationOfPayeeAPI) Identify(ctx context.Context, account AccountAttributes) (AccountAttributes, error) {
+	doc, err := createDocument(
+		ctx,
+		c.api,
+		BaseURL+"/v1/organisation/account-identifications",
+		accountIdentification{
+			Type:       "account_identifications",
+			Attributes: account,
+		},
+	)
+	if err != nil {
+		return AccountAttributes{}, err
+	}
+	return doc.Data.Attributes, nil
+}
@@ -0,0 +1,75 @@
+package form3api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestConfirmationOfPayeeConfirm(t *testing.T) {
+	for _, test := range []struct {
+		message  string
+		expected PayeeMatch
+	}{
+		{
+			message:  `{"data": {"attributes": {"matched": true}}}`,
+			expected: PayeeMatch{Result: MatchExact},
+		},
+		{
+			message: `{"data": {"attributes": {"matched": false, "reason_code": "MBAM", "actual_name": "Samantha Holder"}}}`,
+			expected: PayeeMatch{
+				Result:        MatchClose,
+				SuggestedName: "Samantha Holder",
+				ReasonCode:    ReasonMayBeMatch,
+			},
+		},
+		{
+			message:  `{"data": {"attributes": {"matched": false, "reason_code": "ANNM"}}}`,
+			expected: PayeeMatch{Result: MatchNone, ReasonCode: ReasonNameNoMatch},
+		},
+	} {
+		var body bytes.Buffer
+		c := NewConfirmationOfPayeeAPI(WithHttpClient(newClientRespondingWith(test.message, &body)))
+
+		match, err := c.Confirm(context.Background(), AccountAttributes{
+			AccountNumber: "41426819",
+			BankID:        "400300",
+			Name:          []string{"Sam", "Holder"},
+		})
+		if err != nil {
+			t.Fatal("no error expected, got:", err)
+		}
+		if match != test.expected {
+			t.Errorf("expected %+v, got %+v", test.expected, match)
+		}
+
+		var sent Document[payeeCheck]
+		if err := json.Unmarshal(body.Bytes(), &sent); err != nil {
+			t.Fatal("could not decode request body:", err)
+		}
+		if sent.Data.Attributes.Name != "Sam Holder" || sent.Data.Attributes.BankID != "400300" {
+			t.Errorf("unexpected request: %+v", sent.Data.Attributes)
+		}
+	}
+}
+
+func TestConfirmationOfPayeeIdentify(t *testing.T) {
+	const message = `{"data": {"attributes": {
+		"account_number": "41426819",
+		"bank_id": "400300",
+		"bic": "NWBKGB22",
+		"name": ["Samantha Holder"]
+	}}}`
+
+	var body bytes.Buffer
+	c := NewConfirmationOfPayeeAPI(WithHttpClient(newClientRespondingWith(message, &body)))
+
+	attrs, err := c.Identify(context.Background(), AccountAttributes{Iban: "GB11NWBK40030041426819"})
+	if err != nil {
+		t.Fatal("no error expected, got:", err)
+	}
+	if attrs.Bic != "NWBKGB22" || len(attrs.Name) != 1 || attrs.Name[0] != "Samantha Holder" {
+		t.Errorf("unexpected attributes: %+v", attrs)
+	}
+}
@@ -0,0 +1,120 @@
+package form3api
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// BankDetails describes a bank found in a directory.
+type BankDetails struct {
+	BankID     string   `json:"bank_id,omitempty"`
+	BankIDCode string   `json:"bank_id_code,omitempty"`
+	Bic        string   `json:"bic,omitempty"`
+	Country    string   `json:"country,omitempty"`
+	Name       string   `json:"name,omitempty"`
+	Address    []string `json:"address,omitempty"`
+}
+
+// Directory resolves bank identifiers to bank details. Lookups of unknown
+// banks fail with ErrNotFound.
+type Directory interface {
+	// LookupBankID resolves a national bank identifier, for ex. a UK sort
+	// code or a German BLZ, of the bank in the country.
+	LookupBankID(ctx context.Context, country, bankID string) (BankDetails, error)
+
+	// LookupBic resolves a BIC.
+	LookupBic(ctx context.Context, bic string) (BankDetails, error)
+}
+
+type bankDetailsResource struct {
+	Attributes BankDetails `json:"attributes"`
+}
+
+type directoryAPI struct {
+	api *api
+}
+
+// NewDirectoryAPI creates a client of the Form3 bank directories. It accepts
+// the same options as NewAPI.
+// See https://www.api-docs.form3.tech/api/schemes/fps-direct/validations
+func NewDirectoryAPI(options ...func(*api)) Directory {
+	return &directoryAPI{
+		api: newAPI(options...),
+	}
+}
+
+func (d *directoryAPI) lookup(ctx context.Context, url string) (BankDetails, error) {
+	doc, err := fetchDocument[bankDetailsResource](ctx, d.api, url)
+	if err != nil {
+		return BankDetails{}, err
+	}
+	return doc.Data.Attributes, nil
+}
+
+func (d *directoryAPI) LookupBankID(ctx context.Context, country, bankID string) (BankDetails, error) {
+	ret, err := d.lookup(ctx, fmt.Sprintf(
+		"%s/v1/validations/%s/bankid/%s",
+		BaseURL,
+		url.PathEscape(strings.ToLower(country)),
+		url.PathEscape(bankID),
+	))
+	if err != nil {
+		return BankDetails{}, err
+	}
+	if len(ret.Country) == 0 {
+		ret.Country = strings.ToUpper(country)
+	}
+	return ret, nil
+}
+
+func (d *directoryAPI) LookupBic(ctx context.Context, bic string) (BankDetails, error) {
+	return d.lookup(ctx, fmt.Sprintf("%s/v1/validations/bics/%s", BaseURL, url.PathEscape(bic)))
+}
+
+// EnrichAccount fills Bic, BankID and BankIDCode attributes of the account,
+// which are missing, with details found in the directory. The bank is looked
+// up by BankID and Country attributes, or by Bic, when BankID isn't set.
+// Attributes that are already set are never overwritten.
+func EnrichAccount(ctx context.Context, directory Directory, data AccountData) (AccountData, error) {
+	if data.Attributes == nil {
+		return data, nil
+	}
+	attrs := data.Attributes
+	if len(attrs.Bic) > 0 && len(attrs.BankID) > 0 && len(attrs.BankIDCode) > 0 {
+		return data, nil
+	}
+
+	var (
+		bank BankDetails
+		err  error
+	)
+	switch {
+	case len(attrs.BankID) > 0 && attrs.Country != nil:
+		bank, err = directory.LookupBankID(ctx, *attrs.Country, attrs.BankID)
+	case len(attrs.Bic) > 0:
+		bank, err = directory.LookupBic(ctx, attrs.Bic)
+	default:
+		return data, nil
+	}
+	if err != nil {
+		return AccountData{}, err
+	}
+
+	data = data.clone()
+	attrs = data.Attributes
+	if len(attrs.Bic) == 0 {
+		attrs.Bic = bank.Bic
+	}
+	if len(attrs.BankID) == 0 {
+		attrs.BankID = bank.BankID
+	}
+	if len(attrs.BankIDCode) == 0 {
+		attrs.BankIDCode = bank.BankIDCode
+	}
+	if attrs.Country == nil && len(bank.Country) > 0 {
+		attrs.Country = String(bank.Country)
+	}
+	return data, nil
+}
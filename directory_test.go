@@ -0,0 +1,44 @@
+package form3api
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestDirectoryAPI(t *testing.T) {
+	var urls []string
+	d := NewDirectoryAPI(WithHttpClient(newClientEchoingData(&urls)))
+	ctx := context.Background()
+
+	bank, err := d.LookupBankID(ctx, "GB", "400300")
+	if err != nil {
+		t.Fatal("no error expected, got:", err)
+	}
+	if bank.Country != "GB" {
+		t.Error("unexpected country:", bank.Country)
+	}
+	if _, err := d.LookupBic(ctx, "NWBKGB22"); err != nil {
+		t.Fatal("no error expected, got:", err)
+	}
+
+	expected := []string{
+		"GET " + BaseURL + "/v1/validations/gb/bankid/400300",
+		"GET " + BaseURL + "/v1/validations/bics/NWBKGB22",
+	}
+	if !reflect.DeepEqual(urls, expected) {
+		t.Errorf("unexpected requests: %v", urls)
+	}
+}
+
+func TestDirectoryAPINotFound(t *testing.T) {
+	d := NewDirectoryAPI(WithHttpClient(newClientReturningStatusCode(404)))
+
+	_, err := d.LookupBic(context.Background(), "FOOBAR22")
+
+	var notFound *ErrNotFound
+	if !errors.As(err, &notFound) {
+		t.Error("expected not found error, got:", err)
+	}
+}
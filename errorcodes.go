@@ -0,0 +1,183 @@
+package form3api
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrorCategory groups errors returned by the Form3 API by their cause.
+type ErrorCategory int
+
+const (
+	CategoryUnknown ErrorCategory = iota
+	// The request was malformed or some of the fields were rejected.
+	CategoryValidation
+	// The resource with the same ID already exists.
+	CategoryDuplicate
+	// The version passed along with the request doesn't match the current
+	// version of the resource.
+	CategoryVersionMismatch
+	// The client is not allowed to access the resource.
+	CategoryPermission
+	// The client has been throttled by the server.
+	CategoryThrottling
+)
+
+func (c ErrorCategory) String() string {
+	switch c {
+	case CategoryValidation:
+		return "validation"
+	case CategoryDuplicate:
+		return "duplicate"
+	case CategoryVersionMismatch:
+		return "version mismatch"
+	case CategoryPermission:
+		return "permission"
+	case CategoryThrottling:
+		return "throttling"
+	default:
+		return "unknown"
+	}
+}
+
+// ErrorCode is a machine-readable identifier of a known Form3 error. Codes
+// are comparable with errors.Is, for ex.:
+//
+//	errors.Is(err, form3api.CodeDuplicateAccount)
+type ErrorCode string
+
+const (
+	CodeUnknown           ErrorCode = ""
+	CodeValidationFailure ErrorCode = "validation_failure"
+	CodeMalformedRequest  ErrorCode = "malformed_request"
+	CodeDuplicateAccount  ErrorCode = "duplicate_account"
+	CodeInvalidVersion    ErrorCode = "invalid_version"
+	CodeUnauthorized      ErrorCode = "unauthorized"
+	CodeAccessDenied      ErrorCode = "access_denied"
+	CodeInvalidGrant      ErrorCode = "invalid_grant"
+	CodeTooManyRequests   ErrorCode = "too_many_requests"
+)
+
+func (c ErrorCode) Error() string {
+	if c == CodeUnknown {
+		return "unknown error"
+	}
+	return string(c)
+}
+
+// Category returns the category the error code belongs to.
+func (c ErrorCode) Category() ErrorCategory {
+	switch c {
+	case CodeValidationFailure, CodeMalformedRequest:
+		return CategoryValidation
+	case CodeDuplicateAccount:
+		return CategoryDuplicate
+	case CodeInvalidVersion:
+		return CategoryVersionMismatch
+	case CodeUnauthorized, CodeAccessDenied, CodeInvalidGrant:
+		return CategoryPermission
+	case CodeTooManyRequests:
+		return CategoryThrottling
+	default:
+		return CategoryUnknown
+	}
+}
+
+// errorCatalogue lists known Form3 error messages. The server doesn't return
+// stable error codes for all of them, so we recognise them by the status code
+// and a fragment of the message.
+var errorCatalogue = []struct {
+	statusCode int
+	message    string
+	code       ErrorCode
+}{
+	{statusCode: 400, message: "validation failure", code: CodeValidationFailure},
+	{statusCode: 400, message: "message parsing failed", code: CodeMalformedRequest},
+	{statusCode: 409, message: "duplicate", code: CodeDuplicateAccount},
+	{statusCode: 409, message: "invalid version", code: CodeInvalidVersion},
+}
+
+func lookupErrorCode(statusCode int, code, message string) ErrorCode {
+	// The server may already speak our language.
+	if c := ErrorCode(code); c != CodeUnknown && c.Category() != CategoryUnknown {
+		return c
+	}
+
+	message = strings.ToLower(message)
+	for _, e := range errorCatalogue {
+		if e.statusCode == statusCode && strings.Contains(message, e.message) {
+			return e.code
+		}
+	}
+
+	switch statusCode {
+	case 400, 422:
+		return CodeValidationFailure
+	case 401:
+		return CodeUnauthorized
+	case 403:
+		return CodeAccessDenied
+	case 429:
+		return CodeTooManyRequests
+	default:
+		return CodeUnknown
+	}
+}
+
+type codedError interface {
+	Code() ErrorCode
+}
+
+// Code extracts a machine-readable error code from the err chain, or
+// CodeUnknown if there is none.
+func Code(err error) ErrorCode {
+	var e codedError
+	if errors.As(err, &e) {
+		return e.Code()
+	}
+	return CodeUnknown
+}
+
+// Category returns the category of the err, or CategoryUnknown if the error
+// wasn't returned by the Form3 API.
+func Category(err error) ErrorCategory {
+	return Code(err).Category()
+}
+
+// IsValidation reports whether the request has been rejected because of
+// invalid or missing fields.
+func IsValidation(err error) bool {
+	return Category(err) == CategoryValidation
+}
+
+// IsDuplicate reports whether the resource already exists.
+func IsDuplicate(err error) bool {
+	return Category(err) == CategoryDuplicate
+}
+
+// IsVersionMismatch reports whether the version passed along with the request
+// was invalid.
+func IsVersionMismatch(err error) bool {
+	return Category(err) == CategoryVersionMismatch
+}
+
+// IsPermission reports whether the client is not allowed to access the
+// resource.
+func IsPermission(err error) bool {
+	return Category(err) == CategoryPermission
+}
+
+// IsRetryable reports whether repeating the same request later may succeed.
+func IsRetryable(err error) bool {
+	if Category(err) == CategoryThrottling {
+		return true
+	}
+
+	var e *ErrHttp
+	if errors.As(err, &e) {
+		return isRetryableStatusCode(e.StatusCode)
+	}
+
+	var bg *ErrBadGateway
+	return errors.As(err, &bg)
+}
@@ -0,0 +1,130 @@
+package form3api
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestErrorCodeLookup(t *testing.T) {
+	for _, test := range []struct {
+		err      error
+		code     ErrorCode
+		category ErrorCategory
+	}{
+		{
+			err: newErrConflict(GenericError{
+				ErrorMessage: "Account cannot be created as it violates a duplicate constraint",
+			}),
+			code:     CodeDuplicateAccount,
+			category: CategoryDuplicate,
+		},
+		{
+			err:      newErrConflict(GenericError{ErrorMessage: "invalid version"}),
+			code:     CodeInvalidVersion,
+			category: CategoryVersionMismatch,
+		},
+		{
+			err:      newErrConflict(GenericError{ErrorMessage: "something else"}),
+			code:     CodeUnknown,
+			category: CategoryUnknown,
+		},
+		{
+			err: newErrBadRequest(GenericError{
+				ErrorMessage: "validation failure list:\nvalidation failure list:\ncountry in body is required",
+			}),
+			code:     CodeValidationFailure,
+			category: CategoryValidation,
+		},
+		{
+			err: newErrBadRequest(GenericError{
+				ErrorMessage: "Message parsing failed: Unexpected character",
+				ErrorCode:    "d0a17902-63ed-4cb6-a8e8-fac5ca31b0b7",
+			}),
+			code:     CodeMalformedRequest,
+			category: CategoryValidation,
+		},
+		{
+			err:      newErrBadRequest(GenericError{ErrorCode: "duplicate_account"}),
+			code:     CodeDuplicateAccount,
+			category: CategoryDuplicate,
+		},
+		{
+			err:      newErrForbiden(ForbiddenError{Error: "invalid_grant"}),
+			code:     CodeInvalidGrant,
+			category: CategoryPermission,
+		},
+		{
+			err:      newErrForbiden(ForbiddenError{}),
+			code:     CodeAccessDenied,
+			category: CategoryPermission,
+		},
+		{
+			err:      fmt.Errorf("wrapped: %w", new(ErrTooManyRetries)),
+			code:     CodeTooManyRequests,
+			category: CategoryThrottling,
+		},
+		{
+			err:      errors.New("foo"),
+			code:     CodeUnknown,
+			category: CategoryUnknown,
+		},
+	} {
+		if code := Code(test.err); code != test.code {
+			t.Errorf("%q: expected code %q, got %q", test.err, test.code, code)
+		}
+		if category := Category(test.err); category != test.category {
+			t.Errorf("%q: expected category %s, got %s", test.err, test.category, category)
+		}
+		if test.code != CodeUnknown && !errors.Is(test.err, test.code) {
+			t.Errorf("%q: expected to match code %q", test.err, test.code)
+		}
+		if errors.Is(test.err, CodeUnknown) {
+			t.Errorf("%q: not expected to match unknown code", test.err)
+		}
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	for _, test := range []struct {
+		err      error
+		expected bool
+	}{
+		{err: new(ErrTooManyRetries), expected: true},
+		{err: newErrHttp(503), expected: true},
+		{err: newErrHttp(429), expected: true},
+		{err: newErrHttp(501), expected: false},
+		{err: new(ErrNotFound), expected: false},
+		{err: newErrConflict(GenericError{ErrorMessage: "invalid version"}), expected: false},
+	} {
+		if IsRetryable(test.err) != test.expected {
+			t.Errorf("%q: expected retryable to be %v", test.err, test.expected)
+		}
+	}
+}
+
+func TestApiCreateDuplicateError(t *testing.T) {
+	const message = `{
+		"error_message": "Account cannot be created as it violates a duplicate constraint"
+	}`
+
+	api := NewAPI(
+		WithClock(newTestClock()),
+		WithHttpClient(
+			newClientReturningStatusCodeAndBuffer(
+				409,
+				newBufferCloseWrapper(bytes.NewBufferString(message)),
+			),
+		),
+	)
+
+	_, err := api.Create(context.Background(), AccountData{})
+	if !IsDuplicate(err) {
+		t.Error("expected duplicate error, got:", err)
+	}
+	if IsVersionMismatch(err) {
+		t.Error("unexpected version mismatch error")
+	}
+}
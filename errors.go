@@ -3,6 +3,7 @@ package form3api
 import (
 	"fmt"
 	"net/http"
+	"strings"
 )
 
 type ErrHttp struct {
@@ -13,6 +14,15 @@ func (e ErrHttp) Error() string {
 	return fmt.Sprintf("%d: %s", e.StatusCode, http.StatusText(e.StatusCode))
 }
 
+func (e ErrHttp) Code() ErrorCode {
+	return lookupErrorCode(e.StatusCode, "", "")
+}
+
+func (e *ErrHttp) Is(target error) bool {
+	c, ok := target.(ErrorCode)
+	return ok && c != CodeUnknown && e.Code() == c
+}
+
 func newErrHttp(statusCode int) error {
 	return &ErrHttp{StatusCode: statusCode}
 }
@@ -24,13 +34,107 @@ func (e ErrNotFound) Error() string {
 	return "not found"
 }
 
-// ErrTooManyRetries is the error used when client got throttled past the limit.
-type ErrTooManyRetries struct{}
+// ErrTooManyRetries is the error used when client got throttled past the limit,
+// or the server kept failing until retries ran out. StatusCode and Err hold
+// the status code and the parsed error of the last attempt, if any.
+type ErrTooManyRetries struct {
+	StatusCode int
+	Err        error
+}
 
 func (e ErrTooManyRetries) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("too many retries: %s", e.Err)
+	}
 	return "too many retries"
 }
 
+// Code is CodeTooManyRequests when the client got throttled, or the code of
+// the last error otherwise.
+func (e ErrTooManyRetries) Code() ErrorCode {
+	if e.StatusCode != 0 && e.StatusCode != 429 {
+		return Code(e.Err)
+	}
+	return CodeTooManyRequests
+}
+
+func (e ErrTooManyRetries) Unwrap() error {
+	return e.Err
+}
+
+func (e *ErrTooManyRetries) Is(target error) bool {
+	if c, ok := target.(ErrorCode); ok {
+		return c != CodeUnknown && e.Code() == c
+	}
+	t, ok := target.(*ErrTooManyRetries)
+	return ok && (t.StatusCode == 0 || t.StatusCode == e.StatusCode)
+}
+
+func (o ErrorObject) message() string {
+	if len(o.Detail) > 0 {
+		return o.Detail
+	}
+	return o.Title
+}
+
+// message returns the flat error message, or details of the JSON:API errors
+// when the former is missing.
+func (e GenericError) message() string {
+	if len(e.ErrorMessage) > 0 || len(e.Errors) == 0 {
+		return e.ErrorMessage
+	}
+
+	var b strings.Builder
+	for i, o := range e.Errors {
+		if i > 0 {
+			b.WriteString("; ")
+		}
+		if o.Source != nil && len(o.Source.Pointer) > 0 {
+			fmt.Fprintf(&b, "%s: %s", o.Source.Pointer, o.message())
+		} else {
+			b.WriteString(o.message())
+		}
+	}
+	return b.String()
+}
+
+func (e GenericError) format() string {
+	if len(e.ErrorCode) > 0 {
+		return fmt.Sprintf("%s: %s", e.ErrorCode, e.message())
+	}
+	return e.message()
+}
+
+func (e GenericError) errorCode(statusCode int) ErrorCode {
+	if len(e.ErrorCode) > 0 || len(e.ErrorMessage) > 0 || len(e.Errors) == 0 {
+		return lookupErrorCode(statusCode, e.ErrorCode, e.ErrorMessage)
+	}
+	for _, o := range e.Errors {
+		if c := lookupErrorCode(statusCode, o.Code, o.message()); c != CodeUnknown {
+			return c
+		}
+	}
+	return CodeUnknown
+}
+
+// ErrResponseTooLarge is returned when the response body exceeds the limit set
+// with WithMaxResponseSize.
+type ErrResponseTooLarge struct {
+	Limit int64
+}
+
+func (e ErrResponseTooLarge) Error() string {
+	return fmt.Sprintf("response body exceeds %d bytes", e.Limit)
+}
+
+// ErrCircuitOpen is returned without contacting the server, when the circuit
+// breaker considers the service to be down.
+type ErrCircuitOpen struct{}
+
+func (e ErrCircuitOpen) Error() string {
+	return "circuit breaker is open"
+}
+
 func isSameGenericError(a, b GenericError) bool {
 	return (a.ErrorCode == b.ErrorCode || b.ErrorCode == "") &&
 		(a.ErrorMessage == b.ErrorMessage || b.ErrorMessage == "")
@@ -43,13 +147,17 @@ type ErrBadRequest struct {
 }
 
 func (e ErrBadRequest) Error() string {
-	if len(e.ErrorCode) > 0 {
-		return fmt.Sprintf("%s: %s", e.ErrorCode, e.ErrorMessage)
-	}
-	return e.ErrorMessage
+	return e.format()
+}
+
+func (e ErrBadRequest) Code() ErrorCode {
+	return e.errorCode(400)
 }
 
 func (e *ErrBadRequest) Is(target error) bool {
+	if c, ok := target.(ErrorCode); ok {
+		return c != CodeUnknown && e.Code() == c
+	}
 	t, ok := target.(*ErrBadRequest)
 	if !ok {
 		return false
@@ -57,6 +165,20 @@ func (e *ErrBadRequest) Is(target error) bool {
 	return isSameGenericError(e.GenericError, t.GenericError)
 }
 
+// FieldErrors returns details of the rejected fields keyed by the JSON pointer
+// of the field, for ex. "/data/attributes/bank_id". Only JSON:API style error
+// responses carry such information.
+func (e ErrBadRequest) FieldErrors() map[string][]string {
+	ret := make(map[string][]string)
+	for _, o := range e.Errors {
+		if o.Source == nil || len(o.Source.Pointer) == 0 {
+			continue
+		}
+		ret[o.Source.Pointer] = append(ret[o.Source.Pointer], o.message())
+	}
+	return ret
+}
+
 func newErrBadRequest(e GenericError) error {
 	return &ErrBadRequest{GenericError: e}
 }
@@ -67,7 +189,14 @@ type ErrConflict struct {
 	GenericError
 }
 
+func (e ErrConflict) Code() ErrorCode {
+	return e.errorCode(409)
+}
+
 func (e *ErrConflict) Is(target error) bool {
+	if c, ok := target.(ErrorCode); ok {
+		return c != CodeUnknown && e.Code() == c
+	}
 	t, ok := target.(*ErrConflict)
 	if !ok {
 		return false
@@ -76,16 +205,20 @@ func (e *ErrConflict) Is(target error) bool {
 }
 
 func (e ErrConflict) Error() string {
-	if len(e.ErrorCode) > 0 {
-		return fmt.Sprintf("%s: %s", e.ErrorCode, e.ErrorMessage)
-	}
-	return e.ErrorMessage
+	return e.format()
 }
 
 func newErrConflict(e GenericError) error {
 	return &ErrConflict{GenericError: e}
 }
 
+func (e ForbiddenError) format() string {
+	if len(e.Error) > 0 {
+		return fmt.Sprintf("%s: %s", e.Error, e.ErrorDescription)
+	}
+	return e.ErrorDescription
+}
+
 func isSameForbiddenError(a, b ForbiddenError) bool {
 	return (a.Error == b.Error || b.Error == "") &&
 		(a.ErrorDescription == b.ErrorDescription || b.ErrorDescription == "")
@@ -98,13 +231,17 @@ type ErrForbidden struct {
 }
 
 func (e ErrForbidden) Error() string {
-	if len(e.ForbiddenError.Error) > 0 {
-		return fmt.Sprintf("%s: %s", e.ForbiddenError.Error, e.ErrorDescription)
-	}
-	return e.ErrorDescription
+	return e.ForbiddenError.format()
+}
+
+func (e ErrForbidden) Code() ErrorCode {
+	return lookupErrorCode(403, e.ForbiddenError.Error, e.ErrorDescription)
 }
 
 func (e *ErrForbidden) Is(target error) bool {
+	if c, ok := target.(ErrorCode); ok {
+		return c != CodeUnknown && e.Code() == c
+	}
 	t, ok := target.(*ErrForbidden)
 	if !ok {
 		return false
@@ -115,3 +252,177 @@ func (e *ErrForbidden) Is(target error) bool {
 func newErrForbiden(e ForbiddenError) error {
 	return &ErrForbidden{ForbiddenError: e}
 }
+
+// ErrUnauthorized is returned when the client failed to authenticate, or its
+// credentials have expired.
+type ErrUnauthorized struct {
+	ForbiddenError
+}
+
+func (e ErrUnauthorized) Error() string {
+	return e.ForbiddenError.format()
+}
+
+func (e ErrUnauthorized) Code() ErrorCode {
+	return lookupErrorCode(401, e.ForbiddenError.Error, e.ErrorDescription)
+}
+
+func (e *ErrUnauthorized) Is(target error) bool {
+	if c, ok := target.(ErrorCode); ok {
+		return c != CodeUnknown && e.Code() == c
+	}
+	t, ok := target.(*ErrUnauthorized)
+	if !ok {
+		return false
+	}
+	return isSameForbiddenError(e.ForbiddenError, t.ForbiddenError)
+}
+
+func newErrUnauthorized(e ForbiddenError) error {
+	return &ErrUnauthorized{ForbiddenError: e}
+}
+
+// ErrMethodNotAllowed is returned when the resource doesn't support the
+// requested HTTP method.
+type ErrMethodNotAllowed struct {
+	GenericError
+}
+
+func (e ErrMethodNotAllowed) Error() string {
+	return e.format()
+}
+
+func (e ErrMethodNotAllowed) Code() ErrorCode {
+	return e.errorCode(405)
+}
+
+func (e *ErrMethodNotAllowed) Is(target error) bool {
+	if c, ok := target.(ErrorCode); ok {
+		return c != CodeUnknown && e.Code() == c
+	}
+	t, ok := target.(*ErrMethodNotAllowed)
+	if !ok {
+		return false
+	}
+	return isSameGenericError(e.GenericError, t.GenericError)
+}
+
+func newErrMethodNotAllowed(e GenericError) error {
+	return &ErrMethodNotAllowed{GenericError: e}
+}
+
+// ErrNotAcceptable is returned when the server cannot produce a response in
+// the format requested by the Accept header.
+type ErrNotAcceptable struct {
+	GenericError
+}
+
+func (e ErrNotAcceptable) Error() string {
+	return e.format()
+}
+
+func (e ErrNotAcceptable) Code() ErrorCode {
+	return e.errorCode(406)
+}
+
+func (e *ErrNotAcceptable) Is(target error) bool {
+	if c, ok := target.(ErrorCode); ok {
+		return c != CodeUnknown && e.Code() == c
+	}
+	t, ok := target.(*ErrNotAcceptable)
+	if !ok {
+		return false
+	}
+	return isSameGenericError(e.GenericError, t.GenericError)
+}
+
+func newErrNotAcceptable(e GenericError) error {
+	return &ErrNotAcceptable{GenericError: e}
+}
+
+// ErrUnsupportedMediaType is returned when the server doesn't accept the
+// format of the request body.
+type ErrUnsupportedMediaType struct {
+	GenericError
+}
+
+func (e ErrUnsupportedMediaType) Error() string {
+	return e.format()
+}
+
+func (e ErrUnsupportedMediaType) Code() ErrorCode {
+	return e.errorCode(415)
+}
+
+func (e *ErrUnsupportedMediaType) Is(target error) bool {
+	if c, ok := target.(ErrorCode); ok {
+		return c != CodeUnknown && e.Code() == c
+	}
+	t, ok := target.(*ErrUnsupportedMediaType)
+	if !ok {
+		return false
+	}
+	return isSameGenericError(e.GenericError, t.GenericError)
+}
+
+func newErrUnsupportedMediaType(e GenericError) error {
+	return &ErrUnsupportedMediaType{GenericError: e}
+}
+
+// ErrUnprocessableEntity means that the request was well-formed, but the
+// server refused to process its contents.
+type ErrUnprocessableEntity struct {
+	GenericError
+}
+
+func (e ErrUnprocessableEntity) Error() string {
+	return e.format()
+}
+
+func (e ErrUnprocessableEntity) Code() ErrorCode {
+	return e.errorCode(422)
+}
+
+func (e *ErrUnprocessableEntity) Is(target error) bool {
+	if c, ok := target.(ErrorCode); ok {
+		return c != CodeUnknown && e.Code() == c
+	}
+	t, ok := target.(*ErrUnprocessableEntity)
+	if !ok {
+		return false
+	}
+	return isSameGenericError(e.GenericError, t.GenericError)
+}
+
+func newErrUnprocessableEntity(e GenericError) error {
+	return &ErrUnprocessableEntity{GenericError: e}
+}
+
+// ErrBadGateway is returned when a proxy in front of the API didn't get a
+// valid response from the upstream server.
+type ErrBadGateway struct {
+	GenericError
+}
+
+func (e ErrBadGateway) Error() string {
+	return e.format()
+}
+
+func (e ErrBadGateway) Code() ErrorCode {
+	return e.errorCode(502)
+}
+
+func (e *ErrBadGateway) Is(target error) bool {
+	if c, ok := target.(ErrorCode); ok {
+		return c != CodeUnknown && e.Code() == c
+	}
+	t, ok := target.(*ErrBadGateway)
+	if !ok {
+		return false
+	}
+	return isSameGenericError(e.GenericError, t.GenericError)
+}
+
+func newErrBadGateway(e GenericError) error {
+	return &ErrBadGateway{GenericError: e}
+}
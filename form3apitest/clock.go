@@ -0,0 +1,127 @@
+// Package form3apitest provides utilities for testing code that uses the
+// form3api package.
+package form3apitest
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ksinica/form3api"
+)
+
+// Clock is a fake form3api.Clock, which time moves only when advanced. It is
+// safe for concurrent use.
+type Clock struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	now     time.Time
+	pending []*timer
+}
+
+type timer struct {
+	clock    *Clock
+	c        chan time.Time
+	deadline time.Time
+}
+
+func (t *timer) Tick() <-chan time.Time {
+	return t.c
+}
+
+func (t *timer) Stop() bool {
+	return t.clock.remove(t)
+}
+
+// NewClock creates a fake clock set to now.
+func NewClock(now time.Time) *Clock {
+	ret := &Clock{
+		now: now,
+	}
+	ret.cond = sync.NewCond(&ret.mu)
+	return ret
+}
+
+// Now returns the current time of the clock.
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// NewTimer creates a timer, which fires once the clock is advanced by at
+// least d.
+func (c *Clock) NewTimer(d time.Duration) form3api.Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t := &timer{
+		clock:    c,
+		c:        make(chan time.Time, 1),
+		deadline: c.now.Add(d),
+	}
+	if d <= 0 {
+		t.c <- c.now
+		return t
+	}
+
+	c.pending = append(c.pending, t)
+	c.cond.Broadcast()
+	return t
+}
+
+// After is equivalent to NewTimer(d).Tick().
+func (c *Clock) After(d time.Duration) <-chan time.Time {
+	return c.NewTimer(d).Tick()
+}
+
+func (c *Clock) remove(t *timer) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, p := range c.pending {
+		if p == t {
+			c.pending = append(c.pending[:i], c.pending[i+1:]...)
+			c.cond.Broadcast()
+			return true
+		}
+	}
+	return false
+}
+
+// Advance moves the clock forward by d, firing all the timers that are due.
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+
+	pending := c.pending[:0]
+	for _, t := range c.pending {
+		if t.deadline.After(c.now) {
+			pending = append(pending, t)
+			continue
+		}
+		t.c <- c.now
+	}
+	c.pending = pending
+	c.cond.Broadcast()
+}
+
+// Timers returns the number of timers waiting to fire.
+func (c *Clock) Timers() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.pending)
+}
+
+// BlockUntil blocks until at least n timers are waiting to fire. It lets
+// tests wait for the code under test to go to sleep, before advancing the
+// clock.
+func (c *Clock) BlockUntil(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for len(c.pending) < n {
+		c.cond.Wait()
+	}
+}
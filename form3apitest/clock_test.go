@@ -0,0 +1,110 @@
+package form3apitest_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ksinica/form3api"
+	"github.com/ksinica/form3api/form3apitest"
+)
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestClockAdvance(t *testing.T) {
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := form3apitest.NewClock(start)
+
+	first := clock.NewTimer(time.Second)
+	second := clock.NewTimer(time.Minute)
+	stopped := clock.NewTimer(time.Second)
+
+	if !stopped.Stop() {
+		t.Error("expected pending timer to be stopped")
+	}
+	if clock.Timers() != 2 {
+		t.Fatal("unexpected number of timers:", clock.Timers())
+	}
+
+	clock.Advance(time.Second)
+
+	select {
+	case now := <-first.Tick():
+		if !now.Equal(start.Add(time.Second)) {
+			t.Error("unexpected tick time:", now)
+		}
+	default:
+		t.Error("expected the timer to fire")
+	}
+
+	select {
+	case <-second.Tick():
+		t.Error("timer fired too early")
+	case <-stopped.Tick():
+		t.Error("stopped timer fired")
+	default:
+	}
+
+	if first.Stop() {
+		t.Error("expected fired timer not to be stopped")
+	}
+
+	clock.Advance(time.Hour)
+	<-second.Tick()
+
+	if !clock.Now().Equal(start.Add(time.Hour + time.Second)) {
+		t.Error("unexpected time:", clock.Now())
+	}
+}
+
+func TestClockControlsBackOff(t *testing.T) {
+	var attempts int
+
+	client := &http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			attempts++
+			if attempts == 1 {
+				return &http.Response{
+					StatusCode: 503,
+					Body:       http.NoBody,
+					Request:    req,
+				}, nil
+			}
+			return &http.Response{
+				StatusCode: 200,
+				Body:       io.NopCloser(bytes.NewBufferString(`{"data": {"id": "foo"}}`)),
+				Request:    req,
+			}, nil
+		}),
+	}
+
+	clock := form3apitest.NewClock(time.Now())
+	api := form3api.NewAPI(
+		form3api.WithHttpClient(client),
+		form3api.WithClock(clock),
+	)
+
+	done := make(chan error)
+	go func() {
+		_, err := api.Fetch(context.Background(), "foo")
+		done <- err
+	}()
+
+	// Wait for the client to back off, then skip the delay.
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+
+	if err := <-done; err != nil {
+		t.Fatal("no error expected, got:", err)
+	}
+	if attempts != 2 {
+		t.Error("unexpected number of attempts:", attempts)
+	}
+}
@@ -0,0 +1,58 @@
+package form3apitest
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/ksinica/form3api"
+)
+
+// Directory is a form3api.Directory backed by a fixed set of banks. It is safe
+// for concurrent use.
+type Directory struct {
+	mu    sync.RWMutex
+	banks []form3api.BankDetails
+}
+
+// NewDirectory creates a directory of the banks.
+func NewDirectory(banks ...form3api.BankDetails) *Directory {
+	return &Directory{
+		banks: append([]form3api.BankDetails(nil), banks...),
+	}
+}
+
+// Add a bank to the directory.
+func (d *Directory) Add(bank form3api.BankDetails) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.banks = append(d.banks, bank)
+}
+
+func (d *Directory) find(match func(form3api.BankDetails) bool) (form3api.BankDetails, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	for _, bank := range d.banks {
+		if match(bank) {
+			bank.Address = append([]string(nil), bank.Address...)
+			return bank, nil
+		}
+	}
+	return form3api.BankDetails{}, new(form3api.ErrNotFound)
+}
+
+func (d *Directory) LookupBankID(_ context.Context, country, bankID string) (form3api.BankDetails, error) {
+	return d.find(func(bank form3api.BankDetails) bool {
+		return strings.EqualFold(bank.Country, country) && bank.BankID == bankID
+	})
+}
+
+// LookupBic matches both 8 and 11 character BICs of a bank's head office.
+func (d *Directory) LookupBic(_ context.Context, bic string) (form3api.BankDetails, error) {
+	return d.find(func(bank form3api.BankDetails) bool {
+		return strings.EqualFold(bank.Bic, bic) ||
+			strings.EqualFold(strings.TrimSuffix(bank.Bic, "XXX"), strings.TrimSuffix(bic, "XXX"))
+	})
+}
@@ -0,0 +1,63 @@
+package form3apitest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ksinica/form3api"
+	"github.com/ksinica/form3api/form3apitest"
+)
+
+func TestDirectoryEnrichAccount(t *testing.T) {
+	d := form3apitest.NewDirectory(form3api.BankDetails{
+		BankID:     "400300",
+		BankIDCode: "GBDSC",
+		Bic:        "NWBKGB22",
+		Country:    "GB",
+		Name:       "NatWest",
+	})
+	ctx := context.Background()
+
+	for _, attrs := range []form3api.AccountAttributes{
+		{BankID: "400300", Country: form3api.String("GB")},
+		{Bic: "NWBKGB22XXX"},
+	} {
+		data, err := form3api.EnrichAccount(ctx, d, form3api.AccountData{Attributes: &attrs})
+		if err != nil {
+			t.Fatal("no error expected, got:", err)
+		}
+
+		got := data.Attributes
+		if got.BankID != "400300" || got.BankIDCode != "GBDSC" || got.Country == nil || *got.Country != "GB" {
+			t.Errorf("unexpected attributes: %+v", got)
+		}
+		if len(attrs.BankIDCode) > 0 {
+			t.Error("original account was modified")
+		}
+	}
+
+	// Attributes that are set are kept.
+	data, err := form3api.EnrichAccount(ctx, d, form3api.AccountData{
+		Attributes: &form3api.AccountAttributes{
+			BankID:  "400300",
+			Bic:     "NWBKGB2L",
+			Country: form3api.String("GB"),
+		},
+	})
+	if err != nil {
+		t.Fatal("no error expected, got:", err)
+	}
+	if data.Attributes.Bic != "NWBKGB2L" {
+		t.Error("unexpected bic:", data.Attributes.Bic)
+	}
+
+	_, err = form3api.EnrichAccount(ctx, d, form3api.AccountData{
+		Attributes: &form3api.AccountAttributes{Bic: "DEUTDEFF"},
+	})
+
+	var notFound *form3api.ErrNotFound
+	if !errors.As(err, &notFound) {
+		t.Error("expected not found error, got:", err)
+	}
+}
@@ -0,0 +1,100 @@
+package form3api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const (
+	DefaultReadyInterval    = 500 * time.Millisecond
+	DefaultReadyMaxInterval = 5 * time.Second
+)
+
+// HealthStatus is the status reported by the service health endpoint.
+type HealthStatus string
+
+const (
+	HealthUp   HealthStatus = "up"
+	HealthDown HealthStatus = "down"
+)
+
+// ErrNotReady is returned by WaitReady, when the service reported a status
+// other than HealthUp until the context was done.
+type ErrNotReady struct {
+	Status HealthStatus
+}
+
+func (e ErrNotReady) Error() string {
+	return fmt.Sprintf("service not ready, status: %q", e.Status)
+}
+
+func (a *api) Health(ctx context.Context) (HealthStatus, error) {
+	var res struct {
+		Status HealthStatus `json:"status"`
+	}
+
+	err := a.httpDo(
+		ctx,
+		OperationHealth,
+		http.MethodGet,
+		fmt.Sprintf("%s/v1/health", BaseURL),
+		nil,
+		&res,
+	)
+	if err != nil {
+		return "", err
+	}
+	return res.Status, nil
+}
+
+// WaitReadyOptions configures WaitReady. Zero values are replaced with
+// defaults.
+type WaitReadyOptions struct {
+	// Delay after the first unsuccessful check. It doubles after every
+	// following one, up to MaxInterval.
+	Interval    time.Duration
+	MaxInterval time.Duration
+
+	// Clock used for waiting between checks. Defaults to the clock of the
+	// API, when created with NewAPI, or SystemClock.
+	Clock Clock
+}
+
+// WaitReady polls the health endpoint until the service reports HealthUp, or
+// ctx is done. In the latter case the error of the last check is returned.
+func WaitReady(ctx context.Context, client API, opts WaitReadyOptions) error {
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultReadyInterval
+	}
+	if opts.MaxInterval < opts.Interval {
+		opts.MaxInterval = DefaultReadyMaxInterval
+		if opts.MaxInterval < opts.Interval {
+			opts.MaxInterval = opts.Interval
+		}
+	}
+	if opts.Clock == nil {
+		opts.Clock = clockOf(client)
+	}
+
+	delay := opts.Interval
+	for {
+		status, err := client.Health(ctx)
+		switch {
+		case err == nil && status == HealthUp:
+			return nil
+		case err == nil:
+			err = &ErrNotReady{Status: status}
+		}
+
+		if sleepErr := sleepContext(ctx, opts.Clock, delay); sleepErr != nil {
+			return err
+		}
+
+		delay *= 2
+		if delay > opts.MaxInterval {
+			delay = opts.MaxInterval
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package form3api
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func newClientReturningHealth(statuses []string, n *int) *http.Client {
+	return &http.Client{
+		Transport: &testRoundTripper{
+			roundTrip: func(req *http.Request) (*http.Response, error) {
+				if req.URL.Path != "/v1/health" {
+					return nil, errors.New("unexpected path: " + req.URL.Path)
+				}
+
+				status := statuses[len(statuses)-1]
+				if *n < len(statuses) {
+					status = statuses[*n]
+				}
+				*n++
+
+				if status == "" {
+					return nil, errors.New("connection refused")
+				}
+				return &http.Response{
+					StatusCode: 200,
+					Body:       io.NopCloser(bytes.NewBufferString(`{"status": "` + status + `"}`)),
+					Request:    req,
+				}, nil
+			},
+		},
+	}
+}
+
+func TestApiHealth(t *testing.T) {
+	var n int
+	api := NewAPI(WithHttpClient(newClientReturningHealth([]string{"up"}, &n)))
+
+	status, err := api.Health(context.Background())
+	if err != nil {
+		t.Fatal("no error expected, got:", err)
+	}
+	if status != HealthUp {
+		t.Error("unexpected status:", status)
+	}
+}
+
+func TestWaitReady(t *testing.T) {
+	var n int
+	api := NewAPI(
+		WithClock(newTestClock()),
+		WithHttpClient(newClientReturningHealth([]string{"", "down", "up"}, &n)),
+	)
+
+	if err := WaitReady(context.Background(), api, WaitReadyOptions{}); err != nil {
+		t.Fatal("no error expected, got:", err)
+	}
+	if n != 3 {
+		t.Error("expected 3 health checks, got:", n)
+	}
+}
+
+func TestWaitReadyContextDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The service never gets up, and the context is done while waiting for
+	// the next check.
+	clock := newTestClock()
+	clock.newTimer = func(time.Duration) Timer {
+		cancel()
+		return &manualTimer{c: make(chan time.Time)}
+	}
+
+	var n int
+	api := NewAPI(
+		WithClock(clock),
+		WithHttpClient(newClientReturningHealth([]string{"down"}, &n)),
+	)
+
+	err := WaitReady(ctx, api, WaitReadyOptions{})
+
+	var notReady *ErrNotReady
+	if !errors.As(err, &notReady) || notReady.Status != HealthDown {
+		t.Error("expected not ready error, got:", err)
+	}
+	if n != 1 {
+		t.Error("expected a single health check, got:", n)
+	}
+}
@@ -0,0 +1,192 @@
+package form3api
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	DefaultHedgeDelay = 50 * time.Millisecond
+
+	// Number of the most recent Fetch latencies used for computing the
+	// adaptive delay.
+	hedgeLatencyWindow = 100
+	// Adaptive delay isn't used until this many latencies are collected.
+	hedgeMinSamples = 20
+)
+
+// HedgeSettings configures hedged Fetch requests installed with
+// WithHedgedFetch.
+type HedgeSettings struct {
+	// How long to wait for the first request before sending the second one.
+	// Defaults to DefaultHedgeDelay. When Adaptive is set, it is used only
+	// until enough latencies are collected.
+	Delay time.Duration
+
+	// Adaptive makes the delay follow the 95th percentile of recent Fetch
+	// latencies.
+	Adaptive bool
+
+	// Stats, if set, is updated with the outcome of every Fetch.
+	Stats *HedgeStats
+}
+
+// HedgeStats counts hedged Fetch requests. It is safe for concurrent use.
+type HedgeStats struct {
+	requests atomic.Int64
+	hedged   atomic.Int64
+	wins     atomic.Int64
+}
+
+// Requests returns the number of Fetch calls.
+func (s *HedgeStats) Requests() int64 {
+	return s.requests.Load()
+}
+
+// Hedged returns the number of Fetch calls, which sent the second request.
+func (s *HedgeStats) Hedged() int64 {
+	return s.hedged.Load()
+}
+
+// Wins returns the number of Fetch calls answered by the second request.
+func (s *HedgeStats) Wins() int64 {
+	return s.wins.Load()
+}
+
+type hedger struct {
+	settings HedgeSettings
+
+	mu        sync.Mutex
+	latencies []time.Duration
+	next      int
+}
+
+func newHedger(settings HedgeSettings) *hedger {
+	if settings.Delay <= 0 {
+		settings.Delay = DefaultHedgeDelay
+	}
+	if settings.Stats == nil {
+		settings.Stats = new(HedgeStats)
+	}
+	return &hedger{
+		settings: settings,
+	}
+}
+
+func (h *hedger) observe(d time.Duration) {
+	if !h.settings.Adaptive {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if len(h.latencies) < hedgeLatencyWindow {
+		h.latencies = append(h.latencies, d)
+		return
+	}
+	h.latencies[h.next] = d
+	h.next = (h.next + 1) % hedgeLatencyWindow
+}
+
+func (h *hedger) delay() time.Duration {
+	if !h.settings.Adaptive {
+		return h.settings.Delay
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if len(h.latencies) < hedgeMinSamples {
+		return h.settings.Delay
+	}
+
+	sorted := append([]time.Duration(nil), h.latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted[(len(sorted)*95+99)/100-1]
+}
+
+type hedgeResult struct {
+	data  AccountData
+	err   error
+	hedge bool
+}
+
+// do calls fetch and, if it doesn't answer within the hedging delay, calls it
+// once more. The first successful call wins, the other one gets cancelled.
+// An error is returned only when all the calls fail.
+func (h *hedger) do(
+	ctx context.Context,
+	clock Clock,
+	fetch func(context.Context) (AccountData, error),
+) (AccountData, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan hedgeResult, 2)
+	launch := func(hedge bool) {
+		go func() {
+			data, err := fetch(ctx)
+			results <- hedgeResult{data: data, err: err, hedge: hedge}
+		}()
+	}
+
+	h.settings.Stats.requests.Add(1)
+	start := clock.Now()
+	launch(false)
+	pending := 1
+
+	delay := clock.NewTimer(h.delay())
+	defer delay.Stop()
+
+	var errs [2]error
+	tick := delay.Tick()
+	for {
+		select {
+		case <-tick:
+			tick = nil
+			h.settings.Stats.hedged.Add(1)
+			launch(true)
+			pending++
+		case r := <-results:
+			pending--
+			if r.err == nil {
+				// Only successful calls are observed, fast failures would
+				// lower the adaptive delay.
+				h.observe(clock.Now().Sub(start))
+				if r.hedge {
+					h.settings.Stats.wins.Add(1)
+				}
+				return r.data, nil
+			}
+
+			if r.hedge {
+				errs[1] = r.err
+			} else {
+				errs[0] = r.err
+				// There is no point in sending the failed request once more.
+				tick = nil
+			}
+			if pending == 0 && tick == nil {
+				// Error of the first call is more relevant, the second one
+				// might have been rejected by the circuit breaker.
+				if errs[0] != nil {
+					return AccountData{}, errs[0]
+				}
+				return AccountData{}, errs[1]
+			}
+		}
+	}
+}
+
+// WithHedgedFetch makes Fetch send a second, identical request when the first
+// one takes longer than the hedging delay. It trades additional load for lower
+// tail latency.
+func WithHedgedFetch(settings HedgeSettings) func(*api) {
+	return func(a *api) {
+		a.hedger = newHedger(settings)
+	}
+}
@@ -0,0 +1,213 @@
+package form3api
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHedgedFetch(t *testing.T) {
+	var (
+		attempts  int32
+		started   = make(chan struct{})
+		cancelled = make(chan struct{}, 1)
+	)
+
+	client := &http.Client{
+		Transport: &testRoundTripper{
+			roundTrip: func(req *http.Request) (*http.Response, error) {
+				if atomic.AddInt32(&attempts, 1) == 1 {
+					// The first request hangs until it's cancelled.
+					close(started)
+					<-req.Context().Done()
+					cancelled <- struct{}{}
+					return nil, req.Context().Err()
+				}
+				return &http.Response{
+					StatusCode: 200,
+					Body:       io.NopCloser(bytes.NewBufferString(`{"data": {"id": "foo"}}`)),
+					Request:    req,
+				}, nil
+			},
+		},
+	}
+
+	delay := &manualTimer{c: make(chan time.Time)}
+	clock := newTestClock()
+	clock.newTimer = func(d time.Duration) Timer {
+		return delay
+	}
+
+	var stats HedgeStats
+	api := NewAPI(
+		WithHttpClient(client),
+		WithClock(clock),
+		WithHedgedFetch(HedgeSettings{Stats: &stats}),
+	)
+
+	go func() {
+		<-started
+		delay.fire()
+	}()
+
+	data, err := api.Fetch(context.Background(), "foo")
+	if err != nil {
+		t.Fatal("no error expected, got:", err)
+	}
+	if data.ID != "foo" {
+		t.Error("unexpected id:", data.ID)
+	}
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Error("the slower request was not cancelled")
+	}
+
+	if stats.Requests() != 1 || stats.Hedged() != 1 || stats.Wins() != 1 {
+		t.Errorf(
+			"unexpected stats: %d requests, %d hedged, %d wins",
+			stats.Requests(),
+			stats.Hedged(),
+			stats.Wins(),
+		)
+	}
+}
+
+func TestHedgedFetchNotNeeded(t *testing.T) {
+	clock := newTestClock()
+	clock.newTimer = func(d time.Duration) Timer {
+		if d != DefaultHedgeDelay {
+			t.Error("unexpected hedging delay:", d)
+		}
+		return &manualTimer{c: make(chan time.Time)}
+	}
+
+	var stats HedgeStats
+	api := NewAPI(
+		WithHttpClient(
+			newClientReturningStatusCodeAndBuffer(
+				200,
+				newBufferCloseWrapper(bytes.NewBufferString(`{"data": {"id": "foo"}}`)),
+			),
+		),
+		WithClock(clock),
+		WithHedgedFetch(HedgeSettings{Stats: &stats}),
+	)
+
+	if _, err := api.Fetch(context.Background(), "foo"); err != nil {
+		t.Fatal("no error expected, got:", err)
+	}
+
+	if stats.Requests() != 1 || stats.Hedged() != 0 || stats.Wins() != 0 {
+		t.Errorf(
+			"unexpected stats: %d requests, %d hedged, %d wins",
+			stats.Requests(),
+			stats.Hedged(),
+			stats.Wins(),
+		)
+	}
+}
+
+func TestHedgerAdaptiveDelay(t *testing.T) {
+	h := newHedger(HedgeSettings{
+		Delay:    time.Second,
+		Adaptive: true,
+	})
+
+	for i := 1; i < hedgeMinSamples; i++ {
+		h.observe(time.Duration(i) * time.Millisecond)
+	}
+	if d := h.delay(); d != time.Second {
+		t.Error("expected the initial delay until enough samples, got:", d)
+	}
+
+	for i := 1; i <= 2*hedgeLatencyWindow; i++ {
+		h.observe(time.Duration(i) * time.Millisecond)
+	}
+	// Window holds latencies 101..200 ms.
+	if d := h.delay(); d != 195*time.Millisecond {
+		t.Error("unexpected 95th percentile delay:", d)
+	}
+}
+
+func TestHedgedFetchFailedHedge(t *testing.T) {
+	var (
+		attempts    int32
+		started     = make(chan struct{})
+		hedgeFailed = make(chan struct{})
+	)
+
+	client := &http.Client{
+		Transport: &testRoundTripper{
+			roundTrip: func(req *http.Request) (*http.Response, error) {
+				if atomic.AddInt32(&attempts, 1) == 2 {
+					defer close(hedgeFailed)
+					return nil, errors.New("connection reset")
+				}
+				// The first request answers only after the hedge has failed.
+				close(started)
+				<-hedgeFailed
+				return &http.Response{
+					StatusCode: 200,
+					Body:       io.NopCloser(bytes.NewBufferString(`{"data": {"id": "foo"}}`)),
+					Request:    req,
+				}, nil
+			},
+		},
+	}
+
+	delay := &manualTimer{c: make(chan time.Time)}
+	clock := newTestClock()
+	clock.newTimer = func(d time.Duration) Timer {
+		return delay
+	}
+
+	var stats HedgeStats
+	api := NewAPI(
+		WithHttpClient(client),
+		WithClock(clock),
+		WithHedgedFetch(HedgeSettings{Stats: &stats}),
+	)
+
+	go func() {
+		<-started
+		delay.fire()
+	}()
+
+	data, err := api.Fetch(context.Background(), "foo")
+	if err != nil {
+		t.Fatal("no error expected, got:", err)
+	}
+	if data.ID != "foo" {
+		t.Error("unexpected id:", data.ID)
+	}
+	if stats.Hedged() != 1 || stats.Wins() != 0 {
+		t.Errorf("unexpected stats: %d hedged, %d wins", stats.Hedged(), stats.Wins())
+	}
+}
+
+func TestHedgerAllFailed(t *testing.T) {
+	h := newHedger(HedgeSettings{Adaptive: true})
+
+	clock := newTestClock()
+	clock.newTimer = func(time.Duration) Timer {
+		return &manualTimer{c: make(chan time.Time)}
+	}
+
+	errFoo := errors.New("foo")
+	_, err := h.do(context.Background(), clock, func(context.Context) (AccountData, error) {
+		return AccountData{}, errFoo
+	})
+	if !errors.Is(err, errFoo) {
+		t.Error("expected foo error, got:", err)
+	}
+	if len(h.latencies) != 0 {
+		t.Error("latencies of failed calls should not be observed")
+	}
+}
@@ -0,0 +1,80 @@
+package form3api
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"time"
+)
+
+const (
+	DefaultDialTimeout           = 5 * time.Second
+	DefaultKeepAlive             = 30 * time.Second
+	DefaultTLSHandshakeTimeout   = 5 * time.Second
+	DefaultResponseHeaderTimeout = 30 * time.Second
+	DefaultIdleConnTimeout       = 90 * time.Second
+	DefaultMaxIdleConns          = 100
+	DefaultMaxIdleConnsPerHost   = 32
+)
+
+// defaultHttpClient is shared by all API instances, unless overridden with
+// WithHttpClient, so that they share the connection pool.
+var defaultHttpClient = newDefaultHttpClient()
+
+// newDefaultHttpClient returns an http.Client tuned for talking to a single
+// API host: unlike http.DefaultClient, it won't hang forever on a
+// non-responsive server, and keeps more idle connections around for reuse.
+func newDefaultHttpClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   DefaultDialTimeout,
+		KeepAlive: DefaultKeepAlive,
+	}
+	return &http.Client{
+		Transport: &http.Transport{
+			Proxy:                 http.ProxyFromEnvironment,
+			DialContext:           dialer.DialContext,
+			ForceAttemptHTTP2:     true,
+			MaxIdleConns:          DefaultMaxIdleConns,
+			MaxIdleConnsPerHost:   DefaultMaxIdleConnsPerHost,
+			IdleConnTimeout:       DefaultIdleConnTimeout,
+			TLSHandshakeTimeout:   DefaultTLSHandshakeTimeout,
+			ResponseHeaderTimeout: DefaultResponseHeaderTimeout,
+			ExpectContinueTimeout: time.Second,
+		},
+	}
+}
+
+// withOperationTimeout applies the timeout configured for op, unless ctx
+// already has a deadline.
+func (a *api) withOperationTimeout(ctx context.Context, op Operation) (context.Context, context.CancelFunc) {
+	d, ok := a.opTimeouts[op]
+	if !ok {
+		d = a.timeout
+	}
+	if d <= 0 {
+		return ctx, func() {}
+	}
+	if _, ok := ctx.Deadline(); ok {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, d)
+}
+
+// WithTimeout sets the deadline for every operation, including retries, whose
+// context doesn't have one.
+func WithTimeout(d time.Duration) func(*api) {
+	return func(a *api) {
+		a.timeout = d
+	}
+}
+
+// WithOperationTimeout overrides the timeout set with WithTimeout for the op
+// kind of requests.
+func WithOperationTimeout(op Operation, d time.Duration) func(*api) {
+	return func(a *api) {
+		if a.opTimeouts == nil {
+			a.opTimeouts = make(map[Operation]time.Duration)
+		}
+		a.opTimeouts[op] = d
+	}
+}
@@ -0,0 +1,84 @@
+package form3api
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestDefaultHttpClient(t *testing.T) {
+	a := NewAPI().(*api)
+	if a.client == http.DefaultClient {
+		t.Fatal("expected a purpose-built client")
+	}
+
+	transport, ok := a.client.Transport.(*http.Transport)
+	if !ok {
+		t.Fatal("unexpected transport type")
+	}
+	if transport.ResponseHeaderTimeout != DefaultResponseHeaderTimeout {
+		t.Error("unexpected response header timeout:", transport.ResponseHeaderTimeout)
+	}
+	if transport.MaxIdleConnsPerHost != DefaultMaxIdleConnsPerHost {
+		t.Error("unexpected max idle connections per host:", transport.MaxIdleConnsPerHost)
+	}
+	if !transport.ForceAttemptHTTP2 {
+		t.Error("expected HTTP/2 to be enabled")
+	}
+
+	if NewAPI().(*api).client != a.client {
+		t.Error("expected API instances to share the default client")
+	}
+}
+
+func newClientReportingDeadline(deadlines *[]time.Duration) *http.Client {
+	return &http.Client{
+		Transport: &testRoundTripper{
+			roundTrip: func(req *http.Request) (*http.Response, error) {
+				var d time.Duration
+				if deadline, ok := req.Context().Deadline(); ok {
+					d = time.Until(deadline)
+				}
+				*deadlines = append(*deadlines, d)
+				return &http.Response{
+					StatusCode: 200,
+					Body:       io.NopCloser(bytes.NewBufferString(`{"data": {}}`)),
+					Request:    req,
+				}, nil
+			},
+		},
+	}
+}
+
+func TestApiOperationTimeout(t *testing.T) {
+	var deadlines []time.Duration
+
+	api := NewAPI(
+		WithHttpClient(newClientReportingDeadline(&deadlines)),
+		WithTimeout(time.Minute),
+		WithOperationTimeout(OperationFetch, time.Second),
+	)
+
+	api.Create(context.Background(), AccountData{})
+	api.Fetch(context.Background(), "foo")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	api.Fetch(ctx, "foo")
+
+	if len(deadlines) != 3 {
+		t.Fatal("unexpected number of requests:", len(deadlines))
+	}
+	if deadlines[0] <= time.Second || deadlines[0] > time.Minute {
+		t.Error("expected the default timeout, got:", deadlines[0])
+	}
+	if deadlines[1] <= 0 || deadlines[1] > time.Second {
+		t.Error("expected the operation timeout, got:", deadlines[1])
+	}
+	if deadlines[2] <= time.Minute {
+		t.Error("expected the caller's deadline to be kept, got:", deadlines[2])
+	}
+}
@@ -0,0 +1,165 @@
+package form3api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ListOptions selects a page of resources returned by List.
+type ListOptions struct {
+	// Zero-based number of the page.
+	PageNumber int
+	// Number of resources per page. Server default is used when zero.
+	PageSize int
+	// Filters applied to the resources, for ex. {"bank_id": "400300"}.
+	Filter map[string]string
+}
+
+func (o ListOptions) encode() string {
+	var params []string
+
+	if o.PageNumber > 0 {
+		params = append(params, "page[number]="+strconv.Itoa(o.PageNumber))
+	}
+	if o.PageSize > 0 {
+		params = append(params, "page[size]="+strconv.Itoa(o.PageSize))
+	}
+
+	keys := make([]string, 0, len(o.Filter))
+	for k := range o.Filter {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		params = append(
+			params,
+			fmt.Sprintf("filter[%s]=%s", url.QueryEscape(k), url.QueryEscape(o.Filter[k])),
+		)
+	}
+
+	if len(params) == 0 {
+		return ""
+	}
+	return "?" + strings.Join(params, "&")
+}
+
+// parseListOptions parses page and filter parameters of a list link.
+func parseListOptions(link string) (ListOptions, bool) {
+	u, err := url.Parse(link)
+	if err != nil {
+		return ListOptions{}, false
+	}
+
+	var ret ListOptions
+	for k, v := range u.Query() {
+		if len(v) == 0 {
+			continue
+		}
+		switch {
+		case k == "page[number]":
+			if ret.PageNumber, err = strconv.Atoi(v[0]); err != nil {
+				return ListOptions{}, false
+			}
+		case k == "page[size]":
+			if ret.PageSize, err = strconv.Atoi(v[0]); err != nil {
+				return ListOptions{}, false
+			}
+		case strings.HasPrefix(k, "filter[") && strings.HasSuffix(k, "]"):
+			if ret.Filter == nil {
+				ret.Filter = make(map[string]string)
+			}
+			ret.Filter[k[len("filter["):len(k)-1]] = v[0]
+		}
+	}
+	return ret, true
+}
+
+// Next returns options selecting the following page, if there is one.
+func (p Page) Next() (ListOptions, bool) {
+	if p.Links == nil || len(p.Links.Next) == 0 {
+		return ListOptions{}, false
+	}
+	return parseListOptions(p.Links.Next)
+}
+
+// streamDecoder is implemented by responses, which are decoded incrementally
+// instead of being buffered in memory.
+type streamDecoder interface {
+	decodeStream(dec *json.Decoder) error
+}
+
+// listDecoder decodes items of the "data" array of a list response one by
+// one, handing them to fn as soon as they are decoded.
+type listDecoder[T any] struct {
+	fn   func(T) error
+	page Page
+}
+
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if d, ok := tok.(json.Delim); !ok || d != delim {
+		return fmt.Errorf("expected %s, got %v", delim, tok)
+	}
+	return nil
+}
+
+func (l *listDecoder[T]) decodeData(dec *json.Decoder) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	switch tok {
+	case nil:
+		return nil
+	case json.Delim('['):
+	default:
+		return fmt.Errorf("expected data array, got %v", tok)
+	}
+
+	for dec.More() {
+		var item T
+		if err := dec.Decode(&item); err != nil {
+			return err
+		}
+		if err := l.fn(item); err != nil {
+			return err
+		}
+	}
+	return expectDelim(dec, ']')
+}
+
+func (l *listDecoder[T]) decodeStream(dec *json.Decoder) error {
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+
+		switch key, _ := tok.(string); key {
+		case "data":
+			err = l.decodeData(dec)
+		case "links":
+			err = dec.Decode(&l.page.Links)
+		case "meta":
+			err = dec.Decode(&l.page.Meta)
+		default:
+			var skip json.RawMessage
+			err = dec.Decode(&skip)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return expectDelim(dec, '}')
+}
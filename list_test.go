@@ -0,0 +1,188 @@
+package form3api
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+const testAccountListMessage = `{
+	"data": [
+		{"id": "0d209d7f-d07a-4542-947f-5885fddddae2", "type": "accounts"},
+		{"id": "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc", "type": "accounts"}
+	],
+	"links": {
+		"self": "/v1/organisation/accounts?page[number]=0"
+	}
+}`
+
+func TestListOptionsEncode(t *testing.T) {
+	for _, test := range []struct {
+		options  ListOptions
+		expected string
+	}{
+		{options: ListOptions{}, expected: ""},
+		{
+			options:  ListOptions{PageNumber: 2, PageSize: 100},
+			expected: "?page[number]=2&page[size]=100",
+		},
+		{
+			options: ListOptions{
+				Filter: map[string]string{
+					"country": "GB",
+					"bank_id": "400 300",
+				},
+			},
+			expected: "?filter[bank_id]=400+300&filter[country]=GB",
+		},
+	} {
+		if s := test.options.encode(); s != test.expected {
+			t.Errorf("expected %q, got %q", test.expected, s)
+		}
+	}
+}
+
+func newClientReturningList(message string, url *string) *http.Client {
+	return &http.Client{
+		Transport: &testRoundTripper{
+			roundTrip: func(req *http.Request) (*http.Response, error) {
+				*url = req.URL.String()
+				return &http.Response{
+					StatusCode: 200,
+					Body:       io.NopCloser(bytes.NewBufferString(message)),
+					Request:    req,
+				}, nil
+			},
+		},
+	}
+}
+
+func TestApiList(t *testing.T) {
+	var url string
+	api := NewAPI(WithHttpClient(newClientReturningList(testAccountListMessage, &url)))
+
+	var ids []string
+	err := api.List(
+		context.Background(),
+		ListOptions{PageSize: 2},
+		func(data AccountData) error {
+			ids = append(ids, data.ID)
+			return nil
+		},
+	)
+	if err != nil {
+		t.Fatal("no error expected, got:", err)
+	}
+
+	if url != BaseURL+"/v1/organisation/accounts?page%5Bsize%5D=2" &&
+		url != BaseURL+"/v1/organisation/accounts?page[size]=2" {
+		t.Error("unexpected url:", url)
+	}
+
+	expected := []string{
+		"0d209d7f-d07a-4542-947f-5885fddddae2",
+		"ad27e265-9605-4b4b-a0e5-3003ea9cc4dc",
+	}
+	if !reflect.DeepEqual(ids, expected) {
+		t.Error("unexpected ids:", ids)
+	}
+}
+
+func TestApiListStop(t *testing.T) {
+	var url string
+	api := NewAPI(WithHttpClient(newClientReturningList(testAccountListMessage, &url)))
+
+	stop := errors.New("stop")
+
+	var n int
+	err := api.List(context.Background(), ListOptions{}, func(AccountData) error {
+		n++
+		return stop
+	})
+	if !errors.Is(err, stop) {
+		t.Error("expected stop error, got:", err)
+	}
+	if n != 1 {
+		t.Error("expected a single account, got:", n)
+	}
+}
+
+func TestApiListEmpty(t *testing.T) {
+	for _, message := range []string{`{}`, `{"data": null}`, `{"data": []}`} {
+		var url string
+		api := NewAPI(WithHttpClient(newClientReturningList(message, &url)))
+
+		err := api.List(context.Background(), ListOptions{}, func(AccountData) error {
+			t.Error("no accounts expected")
+			return nil
+		})
+		if err != nil {
+			t.Errorf("%s: no error expected, got: %s", message, err)
+		}
+	}
+}
+
+func TestApiListMalformed(t *testing.T) {
+	for _, message := range []string{`[]`, `{"data": {}}`, `{"data": [{]}`} {
+		var url string
+		api := NewAPI(WithHttpClient(newClientReturningList(message, &url)))
+
+		err := api.List(context.Background(), ListOptions{}, func(AccountData) error {
+			return nil
+		})
+		if err == nil {
+			t.Errorf("%s: expected an error", message)
+		}
+	}
+}
+
+func TestApiListPage(t *testing.T) {
+	const message = `{
+		"data": [],
+		"links": {
+			"self": "/v1/organisation/accounts?page%5Bnumber%5D=1&page%5Bsize%5D=2&filter%5Bcountry%5D=GB",
+			"first": "/v1/organisation/accounts?page%5Bnumber%5D=first&page%5Bsize%5D=2",
+			"next": "/v1/organisation/accounts?page%5Bnumber%5D=2&page%5Bsize%5D=2&filter%5Bcountry%5D=GB"
+		},
+		"meta": {"count": 5}
+	}`
+
+	var url string
+	api := NewAPI(WithHttpClient(newClientReturningList(message, &url)))
+
+	page, err := api.(DocumentAPI).ListPage(context.Background(), ListOptions{}, func(AccountData) error {
+		return nil
+	})
+	if err != nil {
+		t.Fatal("no error expected, got:", err)
+	}
+
+	if page.Links == nil || page.Links.First == "" {
+		t.Fatal("expected links, got:", page.Links)
+	}
+	if string(page.Meta) != `{"count": 5}` {
+		t.Error("unexpected meta:", string(page.Meta))
+	}
+
+	next, ok := page.Next()
+	if !ok {
+		t.Fatal("expected the next page")
+	}
+	expected := ListOptions{
+		PageNumber: 2,
+		PageSize:   2,
+		Filter:     map[string]string{"country": "GB"},
+	}
+	if !reflect.DeepEqual(next, expected) {
+		t.Errorf("unexpected next page options: %+v", next)
+	}
+
+	page.Links.Next = ""
+	if _, ok := page.Next(); ok {
+		t.Error("no next page expected")
+	}
+}
@@ -0,0 +1,148 @@
+package form3api
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// MandatesAPI is a client of the Form3 direct debit mandates resource.
+// Requests rejected by Form3 fail with the same errors as the accounts API,
+// for ex. ErrBadRequest or ErrConflict.
+// See https://www.api-docs.form3.tech/api/schemes/bacs/direct-debits/mandates
+type MandatesAPI interface {
+	// Create a new mandate.
+	Create(ctx context.Context, data MandateData) (MandateData, error)
+
+	// Fetch a single mandate using the mandateID.
+	Fetch(ctx context.Context, mandateID string) (MandateData, error)
+
+	// List a page of mandates. See DocumentAPI.ListPage for details.
+	List(ctx context.Context, options ListOptions, fn func(MandateData) error) (Page, error)
+
+	// Cancel the mandate, so that no further direct debits are collected.
+	Cancel(ctx context.Context, mandateID string, data MandateCancellation) (MandateCancellation, error)
+}
+
+// SchemeSEPADirectDebit is the payment scheme of SEPA direct debits.
+const SchemeSEPADirectDebit = "SEPADD"
+
+// SEPA sequence types of mandates.
+const (
+	SequenceFirst     = "FRST"
+	SequenceRecurring = "RCUR"
+	SequenceOneOff    = "OOFF"
+	SequenceFinal     = "FNAL"
+)
+
+// MandateRelationshipAccount is the name of the relationship referencing the
+// account, which the mandate is set up on.
+const MandateRelationshipAccount = "account"
+
+type MandateData struct {
+	Attributes     *MandateAttributes      `json:"attributes,omitempty"`
+	ID             string                  `json:"id,omitempty"`
+	OrganisationID string                  `json:"organisation_id,omitempty"`
+	Type           string                  `json:"type,omitempty"`
+	Version        *int64                  `json:"version,omitempty"`
+	CreatedOn      *time.Time              `json:"created_on,omitempty"`
+	ModifiedOn     *time.Time              `json:"modified_on,omitempty"`
+	Relationships  map[string]Relationship `json:"relationships,omitempty"`
+}
+
+// AccountID returns the ID of the account, which the mandate is set up on.
+func (d MandateData) AccountID() string {
+	if ids := d.RelationshipIDs(MandateRelationshipAccount); len(ids) > 0 {
+		return ids[0]
+	}
+	return ""
+}
+
+// RelationshipIDs returns IDs of resources related to the mandate by name.
+func (d MandateData) RelationshipIDs(name string) []string {
+	return relationshipIDs(d.Relationships, name)
+}
+
+type MandateAttributes struct {
+	CreditorParty  *PaymentParty `json:"creditor_party,omitempty"`
+	DebtorParty    *PaymentParty `json:"debtor_party,omitempty"`
+	PaymentScheme  string        `json:"payment_scheme,omitempty"`
+	Reference      string        `json:"reference,omitempty"`
+	SignatureDate  string        `json:"signature_date,omitempty"`
+	Status         string        `json:"status,omitempty"`
+	StatusReason   string        `json:"status_reason,omitempty"`
+	EffectiveDate  string        `json:"effective_date,omitempty"`
+	CancellationOn *time.Time    `json:"cancellation_on,omitempty"`
+
+	// Scheme-specific attributes, only the one matching PaymentScheme is
+	// expected to be set.
+	Bacs *BacsMandateAttributes `json:"bacs,omitempty"`
+	SEPA *SEPAMandateAttributes `json:"sepa,omitempty"`
+}
+
+type BacsMandateAttributes struct {
+	// Service user number of the originator, issued by Bacs.
+	ServiceUserNumber string `json:"service_user_number,omitempty"`
+	// Bacs transaction code, for ex. "0N" for a new instruction.
+	TransactionCode string `json:"transaction_code,omitempty"`
+}
+
+type SEPAMandateAttributes struct {
+	CreditorSchemeID string `json:"creditor_scheme_id,omitempty"`
+	// One of Sequence constants.
+	SequenceType string `json:"sequence_type,omitempty"`
+	// Core or B2B.
+	LocalInstrument string `json:"local_instrument,omitempty"`
+}
+
+// MandateForAccount returns a mandate set up on the account, which holder is
+// the debtor.
+func MandateForAccount(id string, account AccountData, scheme string) MandateData {
+	debtor := PartyFromAccount(account)
+	return MandateData{
+		ID:             id,
+		OrganisationID: account.OrganisationID,
+		Type:           "mandates",
+		Attributes: &MandateAttributes{
+			DebtorParty:   &debtor,
+			PaymentScheme: scheme,
+		},
+		Relationships: map[string]Relationship{
+			MandateRelationshipAccount: {
+				Data: []ResourceIdentifier{{ID: account.ID, Type: "accounts"}},
+			},
+		},
+	}
+}
+
+type MandateCancellation struct {
+	Attributes *MandateCancellationAttributes `json:"attributes,omitempty"`
+	ID         string                         `json:"id,omitempty"`
+	Type       string                         `json:"type,omitempty"`
+	Version    *int64                         `json:"version,omitempty"`
+	CreatedOn  *time.Time                     `json:"created_on,omitempty"`
+}
+
+type MandateCancellationAttributes struct {
+	ReasonCode string `json:"reason_code,omitempty"`
+	Reason     string `json:"reason,omitempty"`
+}
+
+type mandatesAPI struct {
+	*Resource[MandateData]
+}
+
+// NewMandatesAPI creates a mandates client. It accepts the same options as
+// NewAPI.
+func NewMandatesAPI(options ...func(*api)) MandatesAPI {
+	return &mandatesAPI{
+		Resource: NewResource[MandateData]("/v1/transaction/mandates", options...),
+	}
+}
+
+func (m *mandatesAPI) Cancel(ctx context.Context, mandateID string, data MandateCancellation) (MandateCancellation, error) {
+	return newResource[MandateCancellation](
+		m.api,
+		fmt.Sprintf("%s/%s/cancellations", m.path, mandateID),
+	).Create(ctx, data)
+}
@@ -0,0 +1,100 @@
+package form3api
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestMandatesAPI(t *testing.T) {
+	var urls []string
+	m := NewMandatesAPI(WithHttpClient(newClientEchoingData(&urls)))
+	ctx := context.Background()
+
+	account := AccountData{
+		ID:             "a1",
+		OrganisationID: "o1",
+		Attributes: &AccountAttributes{
+			AccountNumber: "41426819",
+			BankID:        "400300",
+			Name:          []string{"Samantha Holder"},
+		},
+	}
+	mandate := MandateForAccount("m1", account, SchemeBacs)
+	mandate.Attributes.Bacs = &BacsMandateAttributes{
+		ServiceUserNumber: "112233",
+		TransactionCode:   "0N",
+	}
+
+	data, err := m.Create(ctx, mandate)
+	if err != nil {
+		t.Fatal("no error expected, got:", err)
+	}
+	if data.AccountID() != "a1" || data.OrganisationID != "o1" {
+		t.Errorf("unexpected mandate: %+v", data)
+	}
+	if data.Attributes.DebtorParty.AccountNumber != "41426819" {
+		t.Errorf("unexpected debtor: %+v", data.Attributes.DebtorParty)
+	}
+	if data.Attributes.Bacs == nil || data.Attributes.Bacs.ServiceUserNumber != "112233" {
+		t.Errorf("unexpected Bacs attributes: %+v", data.Attributes.Bacs)
+	}
+
+	if _, err := m.Fetch(ctx, "m1"); err != nil {
+		t.Fatal("no error expected, got:", err)
+	}
+
+	cancellation, err := m.Cancel(ctx, "m1", MandateCancellation{
+		ID:         "c1",
+		Attributes: &MandateCancellationAttributes{ReasonCode: "1"},
+	})
+	if err != nil {
+		t.Fatal("no error expected, got:", err)
+	}
+	if cancellation.ID != "c1" {
+		t.Errorf("unexpected cancellation: %+v", cancellation)
+	}
+
+	expected := []string{
+		"POST " + BaseURL + "/v1/transaction/mandates",
+		"GET " + BaseURL + "/v1/transaction/mandates/m1",
+		"POST " + BaseURL + "/v1/transaction/mandates/m1/cancellations",
+	}
+	if !reflect.DeepEqual(urls, expected) {
+		t.Errorf("unexpected requests: %v", urls)
+	}
+}
+
+func TestMandatesAPIErrors(t *testing.T) {
+	for _, test := range []struct {
+		statusCode int
+		message    string
+		check      func(error) bool
+	}{
+		{
+			statusCode: 400,
+			message:    `{"error_message": "validation failure list:\nvalidation failure list:\nservice_user_number in body is required"}`,
+			check:      IsValidation,
+		},
+		{
+			statusCode: 409,
+			message:    `{"error_message": "invalid version"}`,
+			check:      IsVersionMismatch,
+		},
+	} {
+		m := NewMandatesAPI(
+			WithClock(newTestClock()),
+			WithHttpClient(
+				newClientReturningStatusCodeAndBuffer(
+					test.statusCode,
+					newBufferCloseWrapper(bytes.NewBufferString(test.message)),
+				),
+			),
+		)
+
+		if _, err := m.Create(context.Background(), MandateData{}); !test.check(err) {
+			t.Errorf("%d: unexpected error: %v", test.statusCode, err)
+		}
+	}
+}
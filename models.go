@@ -1,11 +1,57 @@
 package form3api
 
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
 // GenericError represents an error message body returned in the case
 // of 400 and 409 HTTP status codes, as defined in:
 // https://www.api-docs.form3.tech/api/schemes/fps-direct/introduction/errors-status-codes
+// Some endpoints return JSON:API style error arrays instead, those end up
+// in Errors.
 type GenericError struct {
-	ErrorMessage string `json:"error_message,omitempty"`
-	ErrorCode    string `json:"error_code,omitempty"`
+	ErrorMessage string        `json:"error_message,omitempty"`
+	ErrorCode    string        `json:"error_code,omitempty"`
+	Errors       []ErrorObject `json:"errors,omitempty"`
+}
+
+// ErrorObject is a single entry of JSON:API errors array.
+// See https://jsonapi.org/format/#error-objects for more details.
+type ErrorObject struct {
+	ID     string       `json:"id,omitempty"`
+	Status string       `json:"status,omitempty"`
+	Code   string       `json:"code,omitempty"`
+	Title  string       `json:"title,omitempty"`
+	Detail string       `json:"detail,omitempty"`
+	Source *ErrorSource `json:"source,omitempty"`
+}
+
+// ErrorSource points to the part of the request that caused the error.
+type ErrorSource struct {
+	// JSON pointer (RFC 6901) to the rejected field, for ex.
+	// "/data/attributes/bank_id".
+	Pointer string `json:"pointer,omitempty"`
+	// Name of the rejected query parameter.
+	Parameter string `json:"parameter,omitempty"`
+}
+
+const attributesPointerPrefix = "/data/attributes/"
+
+// Attribute returns the JSON name of the rejected AccountAttributes field
+// (for ex. "bank_id"), or an empty string when the pointer refers to
+// something else.
+func (s ErrorSource) Attribute() string {
+	if !strings.HasPrefix(s.Pointer, attributesPointerPrefix) {
+		return ""
+	}
+	attr := strings.TrimPrefix(s.Pointer, attributesPointerPrefix)
+	if i := strings.IndexByte(attr, '/'); i >= 0 {
+		attr = attr[:i]
+	}
+	return attr
 }
 
 // ForbiddenError models a message body returnd in the case of HTTP 403
@@ -20,6 +66,19 @@ type ForbiddenError struct {
 // See https://api-docs.form3.tech/api.html#organisation-accounts for
 // more information about fields.
 type AccountData struct {
+	Attributes     *AccountAttributes      `json:"attributes,omitempty"`
+	ID             string                  `json:"id,omitempty"`
+	OrganisationID string                  `json:"organisation_id,omitempty"`
+	Type           string                  `json:"type,omitempty"`
+	Version        *int64                  `json:"version,omitempty"`
+	CreatedOn      *time.Time              `json:"created_on,omitempty"`
+	ModifiedOn     *time.Time              `json:"modified_on,omitempty"`
+	Relationships  map[string]Relationship `json:"relationships,omitempty"`
+}
+
+// accountRequest is AccountData sent in requests. Timestamps and
+// relationships are maintained by the server, so they are left out.
+type accountRequest struct {
 	Attributes     *AccountAttributes `json:"attributes,omitempty"`
 	ID             string             `json:"id,omitempty"`
 	OrganisationID string             `json:"organisation_id,omitempty"`
@@ -27,6 +86,117 @@ type AccountData struct {
 	Version        *int64             `json:"version,omitempty"`
 }
 
+func newAccountRequest(data AccountData) accountRequest {
+	return accountRequest{
+		Attributes:     data.Attributes,
+		ID:             data.ID,
+		OrganisationID: data.OrganisationID,
+		Type:           data.Type,
+		Version:        data.Version,
+	}
+}
+
+// RelationshipIDs returns IDs of resources related to the account by name,
+// for ex. "master_account".
+func (d AccountData) RelationshipIDs(name string) []string {
+	return relationshipIDs(d.Relationships, name)
+}
+
+func relationshipIDs(relationships map[string]Relationship, name string) []string {
+	var ret []string
+	for _, r := range relationships[name].Data {
+		ret = append(ret, r.ID)
+	}
+	return ret
+}
+
+// Links of a JSON:API document or relationship.
+// See https://jsonapi.org/format/#document-links for more details.
+type Links struct {
+	Self  string `json:"self,omitempty"`
+	First string `json:"first,omitempty"`
+	Prev  string `json:"prev,omitempty"`
+	Next  string `json:"next,omitempty"`
+	Last  string `json:"last,omitempty"`
+}
+
+func (l *Links) clone() *Links {
+	if l == nil {
+		return nil
+	}
+	ret := *l
+	return &ret
+}
+
+// ResourceIdentifier identifies a single related resource.
+type ResourceIdentifier struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+}
+
+// Relationship of a resource. Data is always a list, even if the server
+// sent a single resource identifier.
+// See https://jsonapi.org/format/#document-resource-object-relationships
+type Relationship struct {
+	Data  []ResourceIdentifier `json:"data"`
+	Links *Links               `json:"links,omitempty"`
+	Meta  json.RawMessage      `json:"meta,omitempty"`
+}
+
+func (r *Relationship) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		Data  json.RawMessage `json:"data"`
+		Links *Links          `json:"links"`
+		Meta  json.RawMessage `json:"meta"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	*r = Relationship{
+		Links: raw.Links,
+		Meta:  raw.Meta,
+	}
+
+	data := bytes.TrimSpace(raw.Data)
+	switch {
+	case len(data) == 0, bytes.Equal(data, []byte("null")):
+		return nil
+	case data[0] == '{':
+		var id ResourceIdentifier
+		if err := json.Unmarshal(data, &id); err != nil {
+			return err
+		}
+		r.Data = []ResourceIdentifier{id}
+		return nil
+	default:
+		return json.Unmarshal(data, &r.Data)
+	}
+}
+
+func (r Relationship) clone() Relationship {
+	if r.Data != nil {
+		r.Data = append([]ResourceIdentifier(nil), r.Data...)
+	}
+	r.Links = r.Links.clone()
+	r.Meta = cloneRaw(r.Meta)
+	return r
+}
+
+// Document is a JSON:API top-level document holding a single resource.
+type Document[T any] struct {
+	Data  T               `json:"data"`
+	Links *Links          `json:"links,omitempty"`
+	Meta  json.RawMessage `json:"meta,omitempty"`
+}
+
+// Page holds top-level links and meta of a list response. Links.Next is empty
+// on the last page.
+type Page struct {
+	Links *Links
+	Meta  json.RawMessage
+}
+
 type AccountAttributes struct {
 	AccountClassification   *string  `json:"account_classification,omitempty"`
 	AccountMatchingOptOut   *bool    `json:"account_matching_opt_out,omitempty"`
@@ -44,3 +214,75 @@ type AccountAttributes struct {
 	Status                  *string  `json:"status,omitempty"`
 	Switched                *bool    `json:"switched,omitempty"`
 }
+
+// clone returns a deep copy of d, so that it can be handed out to multiple
+// callers.
+func (d AccountData) clone() AccountData {
+	if d.Version != nil {
+		v := *d.Version
+		d.Version = &v
+	}
+	d.CreatedOn = cloneTime(d.CreatedOn)
+	d.ModifiedOn = cloneTime(d.ModifiedOn)
+	if d.Relationships != nil {
+		relationships := make(map[string]Relationship, len(d.Relationships))
+		for k, v := range d.Relationships {
+			relationships[k] = v.clone()
+		}
+		d.Relationships = relationships
+	}
+	if d.Attributes != nil {
+		attrs := d.Attributes.clone()
+		d.Attributes = &attrs
+	}
+	return d
+}
+
+func cloneString(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	return String(*s)
+}
+
+func cloneBool(b *bool) *bool {
+	if b == nil {
+		return nil
+	}
+	v := *b
+	return &v
+}
+
+func cloneTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	v := *t
+	return &v
+}
+
+func cloneRaw(b json.RawMessage) json.RawMessage {
+	if b == nil {
+		return nil
+	}
+	return append(json.RawMessage(nil), b...)
+}
+
+func cloneStrings(s []string) []string {
+	if s == nil {
+		return nil
+	}
+	return append([]string(nil), s...)
+}
+
+func (a AccountAttributes) clone() AccountAttributes {
+	a.AccountClassification = cloneString(a.AccountClassification)
+	a.AccountMatchingOptOut = cloneBool(a.AccountMatchingOptOut)
+	a.AlternativeNames = cloneStrings(a.AlternativeNames)
+	a.Country = cloneString(a.Country)
+	a.JointAccount = cloneBool(a.JointAccount)
+	a.Name = cloneStrings(a.Name)
+	a.Status = cloneString(a.Status)
+	a.Switched = cloneBool(a.Switched)
+	return a
+}
@@ -4,3 +4,8 @@ package form3api
 func String(s string) *string {
 	return &s
 }
+
+// Int64 returns a pointer to an int64 object i.
+func Int64(i int64) *int64 {
+	return &i
+}
@@ -0,0 +1,61 @@
+package form3api
+
+import (
+	"context"
+	"time"
+)
+
+// OrganisationsAPI is a client of the Form3 organisation units resource.
+// See https://www.api-docs.form3.tech/api/tutorials/getting-started/organisations
+type OrganisationsAPI interface {
+	// Create a new organisation unit.
+	Create(ctx context.Context, data OrganisationData) (OrganisationData, error)
+
+	// Fetch a single organisation unit using the organisationID.
+	Fetch(ctx context.Context, organisationID string) (OrganisationData, error)
+
+	// List a page of organisation units. See DocumentAPI.ListPage for details.
+	List(ctx context.Context, options ListOptions, fn func(OrganisationData) error) (Page, error)
+
+	// Delete an organisation unit using the organisationID and the current
+	// version number.
+	Delete(ctx context.Context, organisationID string, version int64) error
+}
+
+// OrganisationData is an organisation unit. Its OrganisationID is the ID of the
+// parent organisation.
+type OrganisationData struct {
+	Attributes     *OrganisationAttributes `json:"attributes,omitempty"`
+	ID             string                  `json:"id,omitempty"`
+	OrganisationID string                  `json:"organisation_id,omitempty"`
+	Type           string                  `json:"type,omitempty"`
+	Version        *int64                  `json:"version,omitempty"`
+	CreatedOn      *time.Time              `json:"created_on,omitempty"`
+	ModifiedOn     *time.Time              `json:"modified_on,omitempty"`
+	Relationships  map[string]Relationship `json:"relationships,omitempty"`
+}
+
+type OrganisationAttributes struct {
+	Name    string `json:"name,omitempty"`
+	Country string `json:"country,omitempty"`
+}
+
+type organisationsAPI struct {
+	*Resource[OrganisationData]
+}
+
+// NewOrganisationsAPI creates an organisation units client. It accepts the
+// same options as NewAPI.
+func NewOrganisationsAPI(options ...func(*api)) OrganisationsAPI {
+	return &organisationsAPI{
+		Resource: NewResource[OrganisationData]("/v1/organisation/units", options...),
+	}
+}
+
+// WithOrganisationID makes account Create fill OrganisationID with id, unless
+// it is already set.
+func WithOrganisationID(id string) func(*api) {
+	return func(a *api) {
+		a.organisationID = id
+	}
+}
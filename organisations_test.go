@@ -0,0 +1,71 @@
+package form3api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestOrganisationsAPI(t *testing.T) {
+	var urls []string
+	o := NewOrganisationsAPI(WithHttpClient(newClientEchoingData(&urls)))
+	ctx := context.Background()
+
+	data, err := o.Create(ctx, OrganisationData{
+		ID:         "o1",
+		Type:       "organisations",
+		Attributes: &OrganisationAttributes{Name: "Foo Ltd"},
+	})
+	if err != nil {
+		t.Fatal("no error expected, got:", err)
+	}
+	if data.Attributes.Name != "Foo Ltd" {
+		t.Errorf("unexpected organisation: %+v", data.Attributes)
+	}
+
+	if _, err := o.Fetch(ctx, "o1"); err != nil {
+		t.Fatal("no error expected, got:", err)
+	}
+	if err := o.Delete(ctx, "o1", 0); err != nil {
+		t.Fatal("no error expected, got:", err)
+	}
+
+	expected := []string{
+		"POST " + BaseURL + "/v1/organisation/units",
+		"GET " + BaseURL + "/v1/organisation/units/o1",
+		"DELETE " + BaseURL + "/v1/organisation/units/o1?version=0",
+	}
+	if !reflect.DeepEqual(urls, expected) {
+		t.Errorf("unexpected requests: %v", urls)
+	}
+}
+
+func TestApiCreateWithOrganisationID(t *testing.T) {
+	for _, test := range []struct {
+		organisationID string
+		expected       string
+	}{
+		{organisationID: "", expected: "default"},
+		{organisationID: "other", expected: "other"},
+	} {
+		var body bytes.Buffer
+		api := NewAPI(
+			WithOrganisationID("default"),
+			WithHttpClient(newClientRespondingWith(testAccountMessage, &body)),
+		)
+
+		if _, err := api.Create(context.Background(), AccountData{OrganisationID: test.organisationID}); err != nil {
+			t.Fatal("no error expected, got:", err)
+		}
+
+		var sent Document[AccountData]
+		if err := json.Unmarshal(body.Bytes(), &sent); err != nil {
+			t.Fatal("could not decode request body:", err)
+		}
+		if sent.Data.OrganisationID != test.expected {
+			t.Errorf("expected organisation id %q, got %q", test.expected, sent.Data.OrganisationID)
+		}
+	}
+}
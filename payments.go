@@ -0,0 +1,245 @@
+package form3api
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// PaymentsAPI is a client of the Form3 payments resource.
+// See https://www.api-docs.form3.tech/api/schemes/fps-direct/payments
+type PaymentsAPI interface {
+	// Create a new payment.
+	Create(ctx context.Context, data PaymentData) (PaymentData, error)
+
+	// Fetch a single payment using the paymentID.
+	Fetch(ctx context.Context, paymentID string) (PaymentData, error)
+
+	// List a page of payments. See DocumentAPI.ListPage for details.
+	List(ctx context.Context, options ListOptions, fn func(PaymentData) error) (Page, error)
+
+	// CreateSubmission submits the payment to the scheme.
+	CreateSubmission(ctx context.Context, paymentID string, data PaymentSubmission) (PaymentSubmission, error)
+
+	// FetchSubmission fetches a submission of the payment.
+	FetchSubmission(ctx context.Context, paymentID, submissionID string) (PaymentSubmission, error)
+
+	// CreateReturn returns a received payment to its sender.
+	CreateReturn(ctx context.Context, paymentID string, data PaymentReturn) (PaymentReturn, error)
+
+	// FetchReturn fetches a return of the payment.
+	FetchReturn(ctx context.Context, paymentID, returnID string) (PaymentReturn, error)
+
+	// CreateReversal reverses a sent payment.
+	CreateReversal(ctx context.Context, paymentID string, data PaymentReversal) (PaymentReversal, error)
+
+	// FetchReversal fetches a reversal of the payment.
+	FetchReversal(ctx context.Context, paymentID, reversalID string) (PaymentReversal, error)
+}
+
+// Payment schemes.
+const (
+	SchemeFPS         = "FPS"
+	SchemeBacs        = "Bacs"
+	SchemeSEPAInstant = "SEPAINSTANT"
+)
+
+// Amount is a decimal amount of money, for ex. "100.21". It is kept as
+// a string, so that no precision is lost.
+type Amount string
+
+// AmountFromMinorUnits returns an amount of units with exponent decimal
+// places, for ex. AmountFromMinorUnits(10021, 2) is "100.21".
+func AmountFromMinorUnits(units int64, exponent uint) Amount {
+	return Amount(new(big.Rat).SetFrac(
+		big.NewInt(units),
+		new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exponent)), nil),
+	).FloatString(int(exponent)))
+}
+
+// MinorUnits converts the amount to a number of minor units with exponent
+// decimal places. Amounts with more decimal places fail.
+func (a Amount) MinorUnits(exponent uint) (int64, error) {
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(string(a)))
+	if !ok {
+		return 0, fmt.Errorf("invalid amount: %q", a)
+	}
+
+	r.Mul(r, new(big.Rat).SetInt(
+		new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exponent)), nil),
+	))
+	if !r.IsInt() || !r.Num().IsInt64() {
+		return 0, fmt.Errorf("amount %q cannot be represented in minor units", a)
+	}
+	return r.Num().Int64(), nil
+}
+
+// PartyAccountWith identifies the bank holding the account of a party.
+type PartyAccountWith struct {
+	BankID     string `json:"bank_id,omitempty"`
+	BankIDCode string `json:"bank_id_code,omitempty"`
+	Bic        string `json:"bic,omitempty"`
+}
+
+// PaymentParty is a debtor or a beneficiary of a payment.
+type PaymentParty struct {
+	AccountName       string            `json:"account_name,omitempty"`
+	AccountNumber     string            `json:"account_number,omitempty"`
+	AccountNumberCode string            `json:"account_number_code,omitempty"`
+	AccountType       *int              `json:"account_type,omitempty"`
+	AccountWith       *PartyAccountWith `json:"account_with,omitempty"`
+	Address           []string          `json:"address,omitempty"`
+	Country           string            `json:"country,omitempty"`
+	Name              string            `json:"name,omitempty"`
+}
+
+// PartyFromAccount returns a party holding the account. IBAN is used as the
+// account number, when set.
+func PartyFromAccount(data AccountData) PaymentParty {
+	var ret PaymentParty
+	if data.Attributes == nil {
+		return ret
+	}
+
+	attrs := data.Attributes
+	ret.AccountNumber = attrs.AccountNumber
+	ret.AccountNumberCode = "BBAN"
+	if len(attrs.Iban) > 0 {
+		ret.AccountNumber = attrs.Iban
+		ret.AccountNumberCode = "IBAN"
+	}
+	if len(attrs.Name) > 0 {
+		ret.AccountName = strings.Join(attrs.Name, " ")
+		ret.Name = ret.AccountName
+	}
+	if attrs.Country != nil {
+		ret.Country = *attrs.Country
+	}
+	ret.AccountWith = &PartyAccountWith{
+		BankID:     attrs.BankID,
+		BankIDCode: attrs.BankIDCode,
+		Bic:        attrs.Bic,
+	}
+	return ret
+}
+
+type PaymentData struct {
+	Attributes     *PaymentAttributes      `json:"attributes,omitempty"`
+	ID             string                  `json:"id,omitempty"`
+	OrganisationID string                  `json:"organisation_id,omitempty"`
+	Type           string                  `json:"type,omitempty"`
+	Version        *int64                  `json:"version,omitempty"`
+	CreatedOn      *time.Time              `json:"created_on,omitempty"`
+	ModifiedOn     *time.Time              `json:"modified_on,omitempty"`
+	Relationships  map[string]Relationship `json:"relationships,omitempty"`
+}
+
+type PaymentAttributes struct {
+	Amount               Amount        `json:"amount,omitempty"`
+	BeneficiaryParty     *PaymentParty `json:"beneficiary_party,omitempty"`
+	Currency             string        `json:"currency,omitempty"`
+	DebtorParty          *PaymentParty `json:"debtor_party,omitempty"`
+	EndToEndReference    string        `json:"end_to_end_reference,omitempty"`
+	NumericReference     string        `json:"numeric_reference,omitempty"`
+	PaymentPurpose       string        `json:"payment_purpose,omitempty"`
+	PaymentScheme        string        `json:"payment_scheme,omitempty"`
+	PaymentType          string        `json:"payment_type,omitempty"`
+	ProcessingDate       string        `json:"processing_date,omitempty"`
+	Reference            string        `json:"reference,omitempty"`
+	SchemePaymentSubType string        `json:"scheme_payment_sub_type,omitempty"`
+	SchemePaymentType    string        `json:"scheme_payment_type,omitempty"`
+	SchemeProcessingType string        `json:"scheme_processing_type,omitempty"`
+	UniqueSchemeID       string        `json:"unique_scheme_id,omitempty"`
+}
+
+type PaymentSubmission struct {
+	Attributes    *PaymentSubmissionAttributes `json:"attributes,omitempty"`
+	ID            string                       `json:"id,omitempty"`
+	Type          string                       `json:"type,omitempty"`
+	Version       *int64                       `json:"version,omitempty"`
+	CreatedOn     *time.Time                   `json:"created_on,omitempty"`
+	ModifiedOn    *time.Time                   `json:"modified_on,omitempty"`
+	Relationships map[string]Relationship      `json:"relationships,omitempty"`
+}
+
+type PaymentSubmissionAttributes struct {
+	SchemeStatusCode            string     `json:"scheme_status_code,omitempty"`
+	SchemeStatusCodeDescription string     `json:"scheme_status_code_description,omitempty"`
+	Status                      string     `json:"status,omitempty"`
+	StatusReason                string     `json:"status_reason,omitempty"`
+	SubmissionDateTime          *time.Time `json:"submission_datetime,omitempty"`
+}
+
+type PaymentReturn struct {
+	Attributes    *PaymentReturnAttributes `json:"attributes,omitempty"`
+	ID            string                   `json:"id,omitempty"`
+	Type          string                   `json:"type,omitempty"`
+	Version       *int64                   `json:"version,omitempty"`
+	CreatedOn     *time.Time               `json:"created_on,omitempty"`
+	ModifiedOn    *time.Time               `json:"modified_on,omitempty"`
+	Relationships map[string]Relationship  `json:"relationships,omitempty"`
+}
+
+type PaymentReturnAttributes struct {
+	Amount     Amount `json:"amount,omitempty"`
+	Currency   string `json:"currency,omitempty"`
+	ReturnCode string `json:"return_code,omitempty"`
+}
+
+type PaymentReversal struct {
+	Attributes    *PaymentReversalAttributes `json:"attributes,omitempty"`
+	ID            string                     `json:"id,omitempty"`
+	Type          string                     `json:"type,omitempty"`
+	Version       *int64                     `json:"version,omitempty"`
+	CreatedOn     *time.Time                 `json:"created_on,omitempty"`
+	ModifiedOn    *time.Time                 `json:"modified_on,omitempty"`
+	Relationships map[string]Relationship    `json:"relationships,omitempty"`
+}
+
+type PaymentReversalAttributes struct {
+	ReversalCode string `json:"reversal_code,omitempty"`
+}
+
+type paymentsAPI struct {
+	*Resource[PaymentData]
+}
+
+// NewPaymentsAPI creates a payments client. It accepts the same options as
+// NewAPI.
+func NewPaymentsAPI(options ...func(*api)) PaymentsAPI {
+	return &paymentsAPI{
+		Resource: NewResource[PaymentData]("/v1/transaction/payments", options...),
+	}
+}
+
+// subresource returns a client of the kind of objects related to the payment,
+// for ex. "submissions".
+func subresource[T any](p *paymentsAPI, paymentID, kind string) *Resource[T] {
+	return newResource[T](p.api, fmt.Sprintf("%s/%s/%s", p.path, paymentID, kind))
+}
+
+func (p *paymentsAPI) CreateSubmission(ctx context.Context, paymentID string, data PaymentSubmission) (PaymentSubmission, error) {
+	return subresource[PaymentSubmission](p, paymentID, "submissions").Create(ctx, data)
+}
+
+func (p *paymentsAPI) FetchSubmission(ctx context.Context, paymentID, submissionID string) (PaymentSubmission, error) {
+	return subresource[PaymentSubmission](p, paymentID, "submissions").Fetch(ctx, submissionID)
+}
+
+func (p *paymentsAPI) CreateReturn(ctx context.Context, paymentID string, data PaymentReturn) (PaymentReturn, error) {
+	return subresource[PaymentReturn](p, paymentID, "returns").Create(ctx, data)
+}
+
+func (p *paymentsAPI) FetchReturn(ctx context.Context, paymentID, returnID string) (PaymentReturn, error) {
+	return subresource[PaymentReturn](p, paymentID, "returns").Fetch(ctx, returnID)
+}
+
+func (p *paymentsAPI) CreateReversal(ctx context.Context, paymentID string, data PaymentReversal) (PaymentReversal, error) {
+	return subresource[PaymentReversal](p, paymentID, "reversals").Create(ctx, data)
+}
+
+func (p *paymentsAPI) FetchReversal(ctx context.Context, paymentID, reversalID string) (PaymentReversal, error) {
+	return subresource[PaymentReversal](p, paymentID, "reversals").Fetch(ctx, reversalID)
+}
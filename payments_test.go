@@ -0,0 +1,156 @@
+package form3api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestAmount(t *testing.T) {
+	for _, test := range []struct {
+		units    int64
+		exponent uint
+		amount   Amount
+	}{
+		{units: 10021, exponent: 2, amount: "100.21"},
+		{units: 5, exponent: 2, amount: "0.05"},
+		{units: -150, exponent: 2, amount: "-1.50"},
+		{units: 42, exponent: 0, amount: "42"},
+	} {
+		if amount := AmountFromMinorUnits(test.units, test.exponent); amount != test.amount {
+			t.Errorf("expected %q, got %q", test.amount, amount)
+		}
+		units, err := test.amount.MinorUnits(test.exponent)
+		if err != nil {
+			t.Errorf("%q: no error expected, got: %s", test.amount, err)
+		}
+		if units != test.units {
+			t.Errorf("%q: expected %d units, got %d", test.amount, test.units, units)
+		}
+	}
+
+	for _, amount := range []Amount{"", "foo", "1.001"} {
+		if _, err := amount.MinorUnits(2); err == nil {
+			t.Errorf("%q: expected an error", amount)
+		}
+	}
+}
+
+func TestPartyFromAccount(t *testing.T) {
+	party := PartyFromAccount(AccountData{
+		Attributes: &AccountAttributes{
+			AccountNumber: "41426819",
+			BankID:        "400300",
+			BankIDCode:    "GBDSC",
+			Bic:           "NWBKGB22",
+			Country:       String("GB"),
+			Name:          []string{"Samantha", "Holder"},
+		},
+	})
+
+	expected := PaymentParty{
+		AccountName:       "Samantha Holder",
+		AccountNumber:     "41426819",
+		AccountNumberCode: "BBAN",
+		AccountWith: &PartyAccountWith{
+			BankID:     "400300",
+			BankIDCode: "GBDSC",
+			Bic:        "NWBKGB22",
+		},
+		Country: "GB",
+		Name:    "Samantha Holder",
+	}
+	if !reflect.DeepEqual(party, expected) {
+		t.Errorf("unexpected party: %+v", party)
+	}
+}
+
+func TestPaymentsAPI(t *testing.T) {
+	var urls []string
+	p := NewPaymentsAPI(WithHttpClient(newClientEchoingData(&urls)))
+	ctx := context.Background()
+
+	payment, err := p.Create(ctx, PaymentData{
+		ID:   "p1",
+		Type: "payments",
+		Attributes: &PaymentAttributes{
+			Amount:        "100.21",
+			Currency:      "GBP",
+			PaymentScheme: SchemeFPS,
+		},
+	})
+	if err != nil {
+		t.Fatal("no error expected, got:", err)
+	}
+	if payment.ID != "p1" || payment.Attributes.Amount != "100.21" {
+		t.Errorf("unexpected payment: %+v", payment)
+	}
+
+	steps := []func() error{
+		func() error { _, err := p.Fetch(ctx, "p1"); return err },
+		func() error {
+			_, err := p.CreateSubmission(ctx, "p1", PaymentSubmission{ID: "s1"})
+			return err
+		},
+		func() error { _, err := p.FetchSubmission(ctx, "p1", "s1"); return err },
+		func() error { _, err := p.CreateReturn(ctx, "p1", PaymentReturn{ID: "r1"}); return err },
+		func() error { _, err := p.FetchReturn(ctx, "p1", "r1"); return err },
+		func() error { _, err := p.CreateReversal(ctx, "p1", PaymentReversal{ID: "v1"}); return err },
+		func() error { _, err := p.FetchReversal(ctx, "p1", "v1"); return err },
+	}
+	for _, step := range steps {
+		if err := step(); err != nil {
+			t.Fatal("no error expected, got:", err)
+		}
+	}
+
+	expected := []string{
+		"POST " + BaseURL + "/v1/transaction/payments",
+		"GET " + BaseURL + "/v1/transaction/payments/p1",
+		"POST " + BaseURL + "/v1/transaction/payments/p1/submissions",
+		"GET " + BaseURL + "/v1/transaction/payments/p1/submissions/s1",
+		"POST " + BaseURL + "/v1/transaction/payments/p1/returns",
+		"GET " + BaseURL + "/v1/transaction/payments/p1/returns/r1",
+		"POST " + BaseURL + "/v1/transaction/payments/p1/reversals",
+		"GET " + BaseURL + "/v1/transaction/payments/p1/reversals/v1",
+	}
+	if !reflect.DeepEqual(urls, expected) {
+		t.Errorf("unexpected requests: %v", urls)
+	}
+}
+
+func TestPaymentsAPIErrors(t *testing.T) {
+	const message = `{"error_message": "validation failure list:\ncurrency in body is required"}`
+
+	p := NewPaymentsAPI(
+		WithClock(newTestClock()),
+		WithHttpClient(
+			newClientReturningStatusCodeAndBuffer(
+				400,
+				newBufferCloseWrapper(bytes.NewBufferString(message)),
+			),
+		),
+	)
+
+	_, err := p.Create(context.Background(), PaymentData{})
+	if !IsValidation(err) {
+		t.Error("expected validation error, got:", err)
+	}
+}
+
+func TestPaymentAttributesEncoding(t *testing.T) {
+	b, err := json.Marshal(PaymentAttributes{
+		Amount:      AmountFromMinorUnits(100, 2),
+		DebtorParty: &PaymentParty{AccountNumber: "41426819"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const expected = `{"amount":"1.00","debtor_party":{"account_number":"41426819"}}`
+	if string(b) != expected {
+		t.Error("unexpected encoding:", string(b))
+	}
+}
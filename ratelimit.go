@@ -0,0 +1,171 @@
+package form3api
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// Rate limiter never slows down below this fraction of the configured
+	// rate.
+	minRateFactor = 0.1
+	// Fraction of the configured rate regained after every request that
+	// wasn't throttled.
+	rateRecoveryFactor = 0.05
+)
+
+// rateLimiter is a token bucket, which adapts its rate when the server
+// throttles us, and then gradually recovers.
+type rateLimiter struct {
+	limit float64
+	burst float64
+
+	mu     sync.Mutex
+	rate   float64
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(ratePerSecond float64, burst int) *rateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &rateLimiter{
+		limit:  ratePerSecond,
+		burst:  float64(burst),
+		rate:   ratePerSecond,
+		tokens: float64(burst),
+	}
+}
+
+// Must be called with l.mu held.
+func (l *rateLimiter) advance(now time.Time) {
+	if !l.last.IsZero() && now.After(l.last) {
+		l.tokens = math.Min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	}
+	l.last = now
+}
+
+// reserve takes a token and returns how long the caller has to wait before
+// using it.
+func (l *rateLimiter) reserve(now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.advance(now)
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+// cancel returns a reserved token, which hasn't been used.
+func (l *rateLimiter) cancel() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.tokens = math.Min(l.burst, l.tokens+1)
+}
+
+// wait blocks until a request can be sent, or ctx is done.
+func (l *rateLimiter) wait(ctx context.Context, clock Clock) error {
+	d := l.reserve(clock.Now())
+	if d == 0 {
+		return nil
+	}
+	if err := sleepContext(ctx, clock, d); err != nil {
+		l.cancel()
+		return err
+	}
+	return nil
+}
+
+// throttled slows the limiter down after the server responded with 429. When
+// retryAfter is positive, no tokens are handed out until it passes.
+func (l *rateLimiter) throttled(now time.Time, retryAfter time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.advance(now)
+	l.rate = math.Max(l.rate/2, l.limit*minRateFactor)
+	if retryAfter > 0 {
+		l.tokens = math.Min(l.tokens, -retryAfter.Seconds()*l.rate)
+	}
+}
+
+// succeeded lets the limiter regain a bit of its rate.
+func (l *rateLimiter) succeeded(now time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.rate < l.limit {
+		l.advance(now)
+		l.rate = math.Min(l.limit, l.rate+l.limit*rateRecoveryFactor)
+	}
+}
+
+// parseRetryAfter parses Retry-After header value, which can be either
+// a number of seconds or an HTTP date.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if len(value) == 0 {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
+
+// limiters returns rate limiters that apply to the op, most general first.
+func (a *api) limiters(op Operation) []*rateLimiter {
+	var ret []*rateLimiter
+	if a.limiter != nil {
+		ret = append(ret, a.limiter)
+	}
+	if l, ok := a.opLimiters[op]; ok {
+		ret = append(ret, l)
+	}
+	return ret
+}
+
+// WithRateLimit caps the number of requests sent by an API instance to
+// ratePerSecond, allowing bursts of up to burst requests. Callers block until
+// they are allowed to proceed or their context is done. The rate is lowered
+// temporarily, whenever the server throttles the client. Non-positive
+// ratePerSecond means unlimited.
+func WithRateLimit(ratePerSecond float64, burst int) func(*api) {
+	return func(a *api) {
+		if ratePerSecond <= 0 {
+			a.limiter = nil
+			return
+		}
+		a.limiter = newRateLimiter(ratePerSecond, burst)
+	}
+}
+
+// WithOperationRateLimit caps the number of requests of the op kind, in
+// addition to the limit set with WithRateLimit. Non-positive ratePerSecond
+// means unlimited.
+func WithOperationRateLimit(op Operation, ratePerSecond float64, burst int) func(*api) {
+	return func(a *api) {
+		if ratePerSecond <= 0 {
+			delete(a.opLimiters, op)
+			return
+		}
+		if a.opLimiters == nil {
+			a.opLimiters = make(map[Operation]*rateLimiter)
+		}
+		a.opLimiters[op] = newRateLimiter(ratePerSecond, burst)
+	}
+}
@@ -0,0 +1,182 @@
+package form3api
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterReserve(t *testing.T) {
+	l, clock := newRateLimiter(2, 2), newTestClock()
+
+	for i, expected := range []time.Duration{
+		0,
+		0,
+		500 * time.Millisecond,
+		time.Second,
+	} {
+		if d := l.reserve(clock.Now()); d != expected {
+			t.Errorf("%d: expected %s delay, got %s", i, expected, d)
+		}
+	}
+
+	clock.advance(2 * time.Second)
+	if d := l.reserve(clock.Now()); d != 0 {
+		t.Error("expected no delay after refill, got:", d)
+	}
+}
+
+func TestRateLimiterThrottledAndRecovery(t *testing.T) {
+	l, clock := newRateLimiter(10, 1), newTestClock()
+
+	l.throttled(clock.Now(), 0)
+	if l.rate != 5 {
+		t.Error("expected rate to be halved, got:", l.rate)
+	}
+
+	for i := 0; i < 10; i++ {
+		l.throttled(clock.Now(), 0)
+	}
+	if l.rate != 1 {
+		t.Error("expected rate to stop at the minimum, got:", l.rate)
+	}
+
+	l.succeeded(clock.Now())
+	if l.rate != 1.5 {
+		t.Error("expected rate to recover gradually, got:", l.rate)
+	}
+
+	for i := 0; i < 100; i++ {
+		l.succeeded(clock.Now())
+	}
+	if l.rate != 10 {
+		t.Error("expected rate to recover to the limit, got:", l.rate)
+	}
+
+	clock.advance(time.Second)
+	l.throttled(clock.Now(), 3*time.Second)
+	if d := l.reserve(clock.Now()); d < 3*time.Second {
+		t.Error("expected to wait at least for retry after, got:", d)
+	}
+}
+
+func TestRateLimiterWaitCancelled(t *testing.T) {
+	l, clock := newRateLimiter(1, 1), newTestClock()
+	l.reserve(clock.Now())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	clock.newTimer = func(d time.Duration) Timer {
+		return &manualTimer{c: make(chan time.Time, 1)}
+	}
+
+	if err := l.wait(ctx, clock); !errors.Is(err, context.Canceled) {
+		t.Fatal("expected context error, got:", err)
+	}
+	if l.tokens != 0 {
+		t.Error("expected token to be returned, got:", l.tokens)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, test := range []struct {
+		value    string
+		expected time.Duration
+	}{
+		{value: "", expected: 0},
+		{value: "5", expected: 5 * time.Second},
+		{value: "-5", expected: 0},
+		{value: "Sat, 01 Jan 2022 00:00:10 GMT", expected: 10 * time.Second},
+		{value: "Fri, 31 Dec 2021 00:00:00 GMT", expected: 0},
+		{value: "foo", expected: 0},
+	} {
+		if d := parseRetryAfter(test.value, now); d != test.expected {
+			t.Errorf("%q: expected %s, got %s", test.value, test.expected, d)
+		}
+	}
+}
+
+func TestApiRateLimitRetryAfter(t *testing.T) {
+	var (
+		attempts int
+		delays   []time.Duration
+	)
+
+	clock := newTestClock()
+	clock.newTimer = func(d time.Duration) Timer {
+		delays = append(delays, d)
+		return new(immediateTimer)
+	}
+
+	api := NewAPI(
+		WithClock(clock),
+		WithHttpClient(&http.Client{
+			Transport: &testRoundTripper{
+				roundTrip: func(req *http.Request) (*http.Response, error) {
+					attempts++
+					if attempts == 1 {
+						return &http.Response{
+							StatusCode: 429,
+							Header:     http.Header{"Retry-After": []string{"30"}},
+							Body:       http.NoBody,
+							Request:    req,
+						}, nil
+					}
+					return &http.Response{
+						StatusCode: 200,
+						Body:       io.NopCloser(bytes.NewBufferString(`{"data": {}}`)),
+						Request:    req,
+					}, nil
+				},
+			},
+		}),
+		WithRateLimit(100, 10),
+		WithOperationRateLimit(OperationFetch, 50, 10),
+	)
+
+	if _, err := api.Fetch(context.Background(), "foo"); err != nil {
+		t.Fatal("no error expected, got:", err)
+	}
+
+	var waited bool
+	for _, d := range delays {
+		if d >= 29*time.Second {
+			waited = true
+		}
+	}
+	if !waited {
+		t.Error("expected the limiter to wait for retry after, got delays:", delays)
+	}
+}
+
+func TestApiRateLimitNonPositive(t *testing.T) {
+	for _, rate := range []float64{0, -1} {
+		a := newAPI(
+			WithRateLimit(rate, 10),
+			WithOperationRateLimit(OperationFetch, rate, 10),
+		)
+		if a.limiter != nil {
+			t.Errorf("rate %v: expected no limiter, got %v", rate, a.limiter)
+		}
+		if l, ok := a.opLimiters[OperationFetch]; ok {
+			t.Errorf("rate %v: expected no operation limiter, got %v", rate, l)
+		}
+	}
+
+	a := newAPI(
+		WithRateLimit(10, 10),
+		WithOperationRateLimit(OperationFetch, 10, 10),
+		WithRateLimit(0, 10),
+		WithOperationRateLimit(OperationFetch, 0, 10),
+	)
+	if a.limiter != nil || len(a.opLimiters) != 0 {
+		t.Error("expected non-positive rates to remove limiters set before")
+	}
+}
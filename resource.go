@@ -0,0 +1,86 @@
+package form3api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// Resource is a client of a Form3 JSON:API resource, which objects are
+// represented by T. It is safe for concurrent use.
+type Resource[T any] struct {
+	api  *api
+	path string
+}
+
+// NewResource creates a client of the resource at path, for ex.
+// "/v1/transaction/payments". It accepts the same options as NewAPI.
+func NewResource[T any](path string, options ...func(*api)) *Resource[T] {
+	return newResource[T](newAPI(options...), path)
+}
+
+func newResource[T any](a *api, path string) *Resource[T] {
+	return &Resource[T]{
+		api:  a,
+		path: path,
+	}
+}
+
+func (r *Resource[T]) url(id string) string {
+	if len(id) == 0 {
+		return BaseURL + r.path
+	}
+	return fmt.Sprintf("%s%s/%s", BaseURL, r.path, id)
+}
+
+// Create a new resource object.
+func (r *Resource[T]) Create(ctx context.Context, data T) (T, error) {
+	doc, err := r.CreateDocument(ctx, data)
+	return doc.Data, err
+}
+
+// CreateDocument is like Create, but returns the whole response document.
+func (r *Resource[T]) CreateDocument(ctx context.Context, data T) (Document[T], error) {
+	return createDocument(ctx, r.api, r.url(""), data)
+}
+
+// Fetch a single resource object using its id.
+func (r *Resource[T]) Fetch(ctx context.Context, id string) (T, error) {
+	doc, err := r.FetchDocument(ctx, id)
+	return doc.Data, err
+}
+
+// FetchDocument is like Fetch, but returns the whole response document.
+func (r *Resource[T]) FetchDocument(ctx context.Context, id string) (Document[T], error) {
+	return fetchDocument[T](ctx, r.api, r.url(id))
+}
+
+// List a page of resource objects. See DocumentAPI.ListPage for details.
+func (r *Resource[T]) List(ctx context.Context, options ListOptions, fn func(T) error) (Page, error) {
+	return listPage(ctx, r.api, r.url("")+options.encode(), fn)
+}
+
+// Patch updates the resource object using its id. Only the fields set in
+// data are changed. Form3 expects data to hold the current version number.
+func (r *Resource[T]) Patch(ctx context.Context, id string, data T) (T, error) {
+	var ret Document[T]
+
+	if err := r.api.httpDo(
+		ctx,
+		OperationPatch,
+		http.MethodPatch,
+		r.url(id),
+		&Document[T]{Data: data},
+		&ret,
+	); err != nil {
+		var zero T
+		return zero, err
+	}
+
+	return ret.Data, nil
+}
+
+// Delete a resource object using its id and the current version number.
+func (r *Resource[T]) Delete(ctx context.Context, id string, version int64) error {
+	return deleteResource(ctx, r.api, r.url(id), version)
+}
@@ -0,0 +1,124 @@
+package form3api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+type testWidget struct {
+	ID      string `json:"id,omitempty"`
+	Type    string `json:"type,omitempty"`
+	Version *int64 `json:"version,omitempty"`
+	Name    string `json:"name,omitempty"`
+}
+
+func TestResource(t *testing.T) {
+	var urls []string
+	r := NewResource[testWidget]("/v1/widgets", WithHttpClient(newClientEchoingData(&urls)))
+	ctx := context.Background()
+
+	w, err := r.Create(ctx, testWidget{ID: "w1", Name: "foo"})
+	if err != nil {
+		t.Fatal("no error expected, got:", err)
+	}
+	if w.Name != "foo" {
+		t.Errorf("unexpected widget: %+v", w)
+	}
+
+	w, err = r.Patch(ctx, "w1", testWidget{ID: "w1", Version: Int64(0), Name: "bar"})
+	if err != nil {
+		t.Fatal("no error expected, got:", err)
+	}
+	if w.Name != "bar" || w.Version == nil {
+		t.Errorf("unexpected widget: %+v", w)
+	}
+
+	if _, err := r.Fetch(ctx, "w1"); err != nil {
+		t.Fatal("no error expected, got:", err)
+	}
+	if err := r.Delete(ctx, "w1", 1); err != nil {
+		t.Fatal("no error expected, got:", err)
+	}
+
+	expected := []string{
+		"POST " + BaseURL + "/v1/widgets",
+		"PATCH " + BaseURL + "/v1/widgets/w1",
+		"GET " + BaseURL + "/v1/widgets/w1",
+		"DELETE " + BaseURL + "/v1/widgets/w1?version=1",
+	}
+	if !reflect.DeepEqual(urls, expected) {
+		t.Errorf("unexpected requests: %v", urls)
+	}
+}
+
+func TestResourceList(t *testing.T) {
+	const message = `{
+		"data": [{"id": "w1"}, {"id": "w2"}],
+		"links": {"next": "/v1/widgets?page%5Bnumber%5D=1"}
+	}`
+
+	var url string
+	r := NewResource[testWidget]("/v1/widgets", WithHttpClient(newClientReturningList(message, &url)))
+
+	var ids []string
+	page, err := r.List(context.Background(), ListOptions{PageSize: 2}, func(w testWidget) error {
+		ids = append(ids, w.ID)
+		return nil
+	})
+	if err != nil {
+		t.Fatal("no error expected, got:", err)
+	}
+	if !reflect.DeepEqual(ids, []string{"w1", "w2"}) {
+		t.Error("unexpected ids:", ids)
+	}
+	if next, ok := page.Next(); !ok || next.PageNumber != 1 {
+		t.Errorf("unexpected next page: %+v", next)
+	}
+}
+
+func TestResourcePatchConflict(t *testing.T) {
+	const message = `{"error_message": "invalid version"}`
+
+	var sent []byte
+	client := &http.Client{
+		Transport: &testRoundTripper{
+			roundTrip: func(req *http.Request) (*http.Response, error) {
+				var err error
+				if sent, err = io.ReadAll(req.Body); err != nil {
+					return nil, err
+				}
+				return &http.Response{
+					StatusCode: 409,
+					Body:       io.NopCloser(bytes.NewBufferString(message)),
+					Request:    req,
+				}, nil
+			},
+		},
+	}
+
+	r := NewResource[testWidget]("/v1/widgets", WithHttpClient(client))
+
+	_, err := r.Patch(context.Background(), "w1", testWidget{ID: "w1", Version: Int64(3)})
+	if !IsVersionMismatch(err) {
+		t.Error("expected version mismatch error, got:", err)
+	}
+
+	var conflict *ErrConflict
+	if !errors.As(err, &conflict) {
+		t.Error("expected conflict error, got:", err)
+	}
+
+	var doc Document[testWidget]
+	if err := json.Unmarshal(sent, &doc); err != nil {
+		t.Fatal("could not decode request body:", err)
+	}
+	if doc.Data.Version == nil || *doc.Data.Version != 3 {
+		t.Error("expected version to be sent, got:", string(sent))
+	}
+}
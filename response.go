@@ -0,0 +1,61 @@
+package form3api
+
+import (
+	"io"
+	"net/http"
+)
+
+const (
+	DefaultMaxResponseSize int64 = 10 << 20
+)
+
+// limitedBody fails with ErrResponseTooLarge once more than limit bytes are
+// read from it. Unlike io.LimitReader it doesn't truncate the body silently.
+type limitedBody struct {
+	body  io.ReadCloser
+	limit int64
+	read  int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.read > b.limit {
+		return 0, &ErrResponseTooLarge{Limit: b.limit}
+	}
+	// Allow reading a single byte past the limit to tell whether there is
+	// anything left.
+	if left := b.limit - b.read + 1; int64(len(p)) > left {
+		p = p[:left]
+	}
+
+	n, err := b.body.Read(p)
+	b.read += int64(n)
+	if b.read > b.limit {
+		return n - int(b.read-b.limit), &ErrResponseTooLarge{Limit: b.limit}
+	}
+	return n, err
+}
+
+func (b *limitedBody) Close() error {
+	return b.body.Close()
+}
+
+// limitResponseBody makes reading more than limit bytes of (decompressed)
+// resp body fail. Non-positive limit disables the check.
+func limitResponseBody(resp *http.Response, limit int64) {
+	if limit <= 0 {
+		return
+	}
+	resp.Body = &limitedBody{
+		body:  resp.Body,
+		limit: limit,
+	}
+}
+
+// WithMaxResponseSize overrides the maximum size of a response body accepted
+// by an API instance, DefaultMaxResponseSize by default. Larger responses fail
+// with ErrResponseTooLarge. Non-positive n disables the limit.
+func WithMaxResponseSize(n int64) func(*api) {
+	return func(a *api) {
+		a.maxResponseSize = n
+	}
+}
@@ -0,0 +1,88 @@
+package form3api
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestApiMaxResponseSize(t *testing.T) {
+	for _, test := range []struct {
+		limit    int64
+		expected bool
+	}{
+		{limit: int64(len(testAccountMessage)), expected: false},
+		{limit: int64(len(testAccountMessage)) - 1, expected: true},
+		{limit: 0, expected: false},
+	} {
+		api := NewAPI(
+			WithMaxResponseSize(test.limit),
+			WithHttpClient(
+				newClientReturningStatusCodeAndBuffer(
+					200,
+					newBufferCloseWrapper(bytes.NewBufferString(testAccountMessage)),
+				),
+			),
+		)
+
+		_, err := api.Fetch(context.Background(), "foo")
+
+		var tooLargeErr *ErrResponseTooLarge
+		if tooLarge := errors.As(err, &tooLargeErr); tooLarge != test.expected {
+			t.Errorf("limit %d: expected too large error %v, got: %v", test.limit, test.expected, err)
+		}
+	}
+}
+
+func TestApiMaxResponseSizeCompressed(t *testing.T) {
+	message := `{"data": {"id": "` + strings.Repeat("a", 1<<16) + `"}}`
+
+	api := NewAPI(
+		WithMaxResponseSize(1024),
+		WithHttpClient(
+			newClientReturningEncodedBuffer(
+				200,
+				"gzip",
+				newBufferCloseWrapper(compressString(t, "gzip", message)),
+			),
+		),
+	)
+
+	_, err := api.Fetch(context.Background(), "foo")
+
+	var tooLarge *ErrResponseTooLarge
+	if !errors.As(err, &tooLarge) || tooLarge.Limit != 1024 {
+		t.Error("expected too large error, got:", err)
+	}
+}
+
+func TestApiMaxResponseSizeCompressedDrain(t *testing.T) {
+	// Random data doesn't compress.
+	b := make([]byte, 1<<20)
+	rand.Read(b)
+	message := `{"data": {"id": "` + base64.StdEncoding.EncodeToString(b) + `"}}`
+
+	buf := compressString(t, "gzip", message)
+	size := buf.Len()
+
+	api := NewAPI(
+		WithMaxResponseSize(1024),
+		WithHttpClient(
+			newClientReturningEncodedBuffer(200, "gzip", newBufferCloseWrapper(buf)),
+		),
+	)
+
+	_, err := api.Fetch(context.Background(), "foo")
+
+	var tooLarge *ErrResponseTooLarge
+	if !errors.As(err, &tooLarge) {
+		t.Error("expected too large error, got:", err)
+	}
+	if read := size - buf.Len(); read > 2*maxDrainSize {
+		t.Errorf("expected a bounded read, got %d of %d bytes", read, size)
+	}
+}
@@ -0,0 +1,112 @@
+package form3api
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// detachedContext carries values of its parent, but is never cancelled along
+// with it.
+type detachedContext struct {
+	parent context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (detachedContext) Err() error {
+	return nil
+}
+
+func (c detachedContext) Value(key any) any {
+	return c.parent.Value(key)
+}
+
+type flightCall struct {
+	done    chan struct{}
+	cancel  context.CancelFunc
+	waiters int
+
+	data AccountData
+	err  error
+}
+
+// flightGroup makes concurrent fetches of the same account share a single
+// request. The shared request is cancelled only when all of its callers have
+// given up.
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall
+}
+
+func newFlightGroup() *flightGroup {
+	return &flightGroup{
+		calls: make(map[string]*flightCall),
+	}
+}
+
+// Must be called with g.mu held.
+func (g *flightGroup) forget(key string, c *flightCall) {
+	if g.calls[key] == c {
+		delete(g.calls, key)
+	}
+}
+
+func (g *flightGroup) do(
+	ctx context.Context,
+	key string,
+	fn func(context.Context) (AccountData, error),
+) (AccountData, error) {
+	g.mu.Lock()
+	c, ok := g.calls[key]
+	if !ok {
+		shared, cancel := context.WithCancel(detachedContext{parent: ctx})
+		c = &flightCall{
+			done:   make(chan struct{}),
+			cancel: cancel,
+		}
+		g.calls[key] = c
+
+		go func() {
+			c.data, c.err = fn(shared)
+
+			g.mu.Lock()
+			g.forget(key, c)
+			g.mu.Unlock()
+
+			cancel()
+			close(c.done)
+		}()
+	}
+	c.waiters++
+	g.mu.Unlock()
+
+	select {
+	case <-c.done:
+		return c.data.clone(), c.err
+	case <-ctx.Done():
+		g.mu.Lock()
+		c.waiters--
+		if c.waiters == 0 {
+			// Nobody is interested in the result anymore.
+			g.forget(key, c)
+			c.cancel()
+		}
+		g.mu.Unlock()
+		return AccountData{}, ctx.Err()
+	}
+}
+
+// WithFetchCoalescing makes concurrent Fetch calls for the same account share
+// a single HTTP request and its result.
+func WithFetchCoalescing() func(*api) {
+	return func(a *api) {
+		a.flights = newFlightGroup()
+	}
+}
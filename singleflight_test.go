@@ -0,0 +1,146 @@
+package form3api
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type blockingClient struct {
+	attempts  int32
+	release   chan struct{}
+	cancelled chan struct{}
+}
+
+func newBlockingClient() *blockingClient {
+	return &blockingClient{
+		release:   make(chan struct{}),
+		cancelled: make(chan struct{}, 1),
+	}
+}
+
+func (c *blockingClient) client() *http.Client {
+	return &http.Client{
+		Transport: &testRoundTripper{
+			roundTrip: func(req *http.Request) (*http.Response, error) {
+				atomic.AddInt32(&c.attempts, 1)
+				select {
+				case <-c.release:
+				case <-req.Context().Done():
+					c.cancelled <- struct{}{}
+					return nil, req.Context().Err()
+				}
+				return &http.Response{
+					StatusCode: 200,
+					Body:       io.NopCloser(bytes.NewBufferString(`{"data": {"id": "foo", "version": 1}}`)),
+					Request:    req,
+				}, nil
+			},
+		},
+	}
+}
+
+func waitForWaiters(t *testing.T, a API, key string, n int) {
+	t.Helper()
+
+	g := a.(*api).flights
+	for start := time.Now(); time.Since(start) < time.Second; time.Sleep(time.Millisecond) {
+		g.mu.Lock()
+		c, ok := g.calls[key]
+		done := ok && c.waiters == n
+		g.mu.Unlock()
+		if done {
+			return
+		}
+	}
+	t.Fatalf("expected %d waiters", n)
+}
+
+func TestFetchCoalescing(t *testing.T) {
+	const callers = 10
+
+	c := newBlockingClient()
+	api := NewAPI(WithHttpClient(c.client()), WithFetchCoalescing())
+
+	var (
+		wg      sync.WaitGroup
+		results = make([]AccountData, callers)
+		errs    = make([]error, callers)
+	)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], errs[i] = api.Fetch(context.Background(), "foo")
+		}(i)
+	}
+
+	waitForWaiters(t, api, "foo", callers)
+	close(c.release)
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&c.attempts); n != 1 {
+		t.Error("expected a single request, got:", n)
+	}
+	for i := range results {
+		if errs[i] != nil {
+			t.Fatal("no error expected, got:", errs[i])
+		}
+		if results[i].ID != "foo" {
+			t.Error("unexpected id:", results[i].ID)
+		}
+	}
+
+	*results[0].Version = 2
+	if *results[1].Version != 1 {
+		t.Error("callers share the same result object")
+	}
+}
+
+func TestFetchCoalescingCancellation(t *testing.T) {
+	c := newBlockingClient()
+	api := NewAPI(WithHttpClient(c.client()), WithFetchCoalescing())
+
+	ctx1, cancel1 := context.WithCancel(context.Background())
+	ctx2, cancel2 := context.WithCancel(context.Background())
+	defer cancel2()
+
+	errs := make(chan error, 2)
+	go func() {
+		_, err := api.Fetch(ctx1, "foo")
+		errs <- err
+	}()
+	go func() {
+		_, err := api.Fetch(ctx2, "foo")
+		errs <- err
+	}()
+	waitForWaiters(t, api, "foo", 2)
+
+	cancel1()
+	if err := <-errs; !errors.Is(err, context.Canceled) {
+		t.Fatal("expected context error, got:", err)
+	}
+
+	select {
+	case <-c.cancelled:
+		t.Fatal("shared request cancelled while there still is a waiter")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	cancel2()
+	if err := <-errs; !errors.Is(err, context.Canceled) {
+		t.Fatal("expected context error, got:", err)
+	}
+
+	select {
+	case <-c.cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("shared request was not cancelled")
+	}
+}
@@ -0,0 +1,82 @@
+package form3api
+
+import (
+	"context"
+	"time"
+)
+
+// SubscriptionsAPI is a client of the Form3 notification subscriptions
+// resource. Subscribed events are delivered to the callback URI, where they
+// can be received with WebhookHandler.
+// See https://www.api-docs.form3.tech/api/tutorials/getting-started/notifications
+type SubscriptionsAPI interface {
+	// Create a new subscription.
+	Create(ctx context.Context, data SubscriptionData) (SubscriptionData, error)
+
+	// Fetch a single subscription using the subscriptionID.
+	Fetch(ctx context.Context, subscriptionID string) (SubscriptionData, error)
+
+	// List a page of subscriptions. See DocumentAPI.ListPage for details.
+	List(ctx context.Context, options ListOptions, fn func(SubscriptionData) error) (Page, error)
+
+	// Delete a subscription using the subscriptionID and the current version
+	// number.
+	Delete(ctx context.Context, subscriptionID string, version int64) error
+}
+
+// Event types of notifications.
+const (
+	EventCreated = "created"
+	EventUpdated = "updated"
+	EventDeleted = "deleted"
+)
+
+// RecordTypeAccounts is the record type of account notifications.
+const RecordTypeAccounts = "accounts"
+
+type SubscriptionData struct {
+	Attributes     *SubscriptionAttributes `json:"attributes,omitempty"`
+	ID             string                  `json:"id,omitempty"`
+	OrganisationID string                  `json:"organisation_id,omitempty"`
+	Type           string                  `json:"type,omitempty"`
+	Version        *int64                  `json:"version,omitempty"`
+	CreatedOn      *time.Time              `json:"created_on,omitempty"`
+	ModifiedOn     *time.Time              `json:"modified_on,omitempty"`
+}
+
+type SubscriptionAttributes struct {
+	CallbackTransport string `json:"callback_transport,omitempty"`
+	CallbackURI       string `json:"callback_uri,omitempty"`
+	Deactivated       *bool  `json:"deactivated,omitempty"`
+	EventType         string `json:"event_type,omitempty"`
+	RecordType        string `json:"record_type,omitempty"`
+	UserID            string `json:"user_id,omitempty"`
+}
+
+// AccountSubscription returns a subscription to account events of the
+// eventType, delivered over HTTP to callbackURI.
+func AccountSubscription(id, organisationID, eventType, callbackURI string) SubscriptionData {
+	return SubscriptionData{
+		ID:             id,
+		OrganisationID: organisationID,
+		Type:           "subscriptions",
+		Attributes: &SubscriptionAttributes{
+			CallbackTransport: "http",
+			CallbackURI:       callbackURI,
+			EventType:         eventType,
+			RecordType:        RecordTypeAccounts,
+		},
+	}
+}
+
+type subscriptionsAPI struct {
+	*Resource[SubscriptionData]
+}
+
+// NewSubscriptionsAPI creates a subscriptions client. It accepts the same
+// options as NewAPI.
+func NewSubscriptionsAPI(options ...func(*api)) SubscriptionsAPI {
+	return &subscriptionsAPI{
+		Resource: NewResource[SubscriptionData]("/v1/notification/subscriptions", options...),
+	}
+}
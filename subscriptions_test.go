@@ -0,0 +1,37 @@
+package form3api
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestSubscriptionsAPI(t *testing.T) {
+	var urls []string
+	s := NewSubscriptionsAPI(WithHttpClient(newClientEchoingData(&urls)))
+	ctx := context.Background()
+
+	data, err := s.Create(ctx, AccountSubscription("s1", "o1", EventUpdated, "https://example.com/hook"))
+	if err != nil {
+		t.Fatal("no error expected, got:", err)
+	}
+	if data.Attributes.RecordType != RecordTypeAccounts || data.Attributes.EventType != EventUpdated {
+		t.Errorf("unexpected subscription: %+v", data.Attributes)
+	}
+
+	if _, err := s.Fetch(ctx, "s1"); err != nil {
+		t.Fatal("no error expected, got:", err)
+	}
+	if err := s.Delete(ctx, "s1", 2); err != nil {
+		t.Fatal("no error expected, got:", err)
+	}
+
+	expected := []string{
+		"POST " + BaseURL + "/v1/notification/subscriptions",
+		"GET " + BaseURL + "/v1/notification/subscriptions/s1",
+		"DELETE " + BaseURL + "/v1/notification/subscriptions/s1?version=2",
+	}
+	if !reflect.DeepEqual(urls, expected) {
+		t.Errorf("unexpected requests: %v", urls)
+	}
+}
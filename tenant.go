@@ -0,0 +1,143 @@
+package form3api
+
+import (
+	"context"
+	"fmt"
+)
+
+// ErrOrganisationMismatch is returned by organisation-scoped views, when an
+// account belongs to another organisation than the view.
+type ErrOrganisationMismatch struct {
+	Expected string
+	Actual   string
+}
+
+func (e ErrOrganisationMismatch) Error() string {
+	return fmt.Sprintf("account belongs to organisation %q, not %q", e.Actual, e.Expected)
+}
+
+// MultiTenantAPI hands out API views scoped to single organisations.
+type MultiTenantAPI interface {
+	// ForOrganisation returns a view of accounts of the organisation. Views
+	// share the connection pool, rate limiters, circuit breaker and the rest
+	// of the configuration, so they are cheap to create.
+	ForOrganisation(organisationID string) API
+}
+
+type multiTenantAPI struct {
+	api *api
+}
+
+// NewMultiTenantAPI creates a client used on behalf of multiple organisations.
+// It accepts the same options as NewAPI.
+func NewMultiTenantAPI(options ...func(*api)) MultiTenantAPI {
+	return &multiTenantAPI{
+		api: newAPI(options...),
+	}
+}
+
+func (m *multiTenantAPI) ForOrganisation(organisationID string) API {
+	return &organisationAPI{
+		api:            m.api,
+		organisationID: organisationID,
+	}
+}
+
+// organisationAPI rejects accounts of other organisations client-side, before
+// they are sent or handed out.
+type organisationAPI struct {
+	api            *api
+	organisationID string
+}
+
+func (o *organisationAPI) check(data AccountData) error {
+	if data.OrganisationID != o.organisationID {
+		return &ErrOrganisationMismatch{
+			Expected: o.organisationID,
+			Actual:   data.OrganisationID,
+		}
+	}
+	return nil
+}
+
+func (o *organisationAPI) Create(ctx context.Context, data AccountData) (AccountData, error) {
+	doc, err := o.CreateDocument(ctx, data)
+	if err != nil {
+		return AccountData{}, err
+	}
+	return doc.Data, nil
+}
+
+func (o *organisationAPI) CreateDocument(ctx context.Context, data AccountData) (Document[AccountData], error) {
+	if len(data.OrganisationID) == 0 {
+		data.OrganisationID = o.organisationID
+	}
+	if err := o.check(data); err != nil {
+		return Document[AccountData]{}, err
+	}
+
+	doc, err := o.api.CreateDocument(ctx, data)
+	if err != nil {
+		return Document[AccountData]{}, err
+	}
+	if err := o.check(doc.Data); err != nil {
+		return Document[AccountData]{}, err
+	}
+	return doc, nil
+}
+
+func (o *organisationAPI) Fetch(ctx context.Context, accountID string) (AccountData, error) {
+	data, err := o.api.Fetch(ctx, accountID)
+	if err != nil {
+		return AccountData{}, err
+	}
+	if err := o.check(data); err != nil {
+		return AccountData{}, err
+	}
+	return data, nil
+}
+
+func (o *organisationAPI) FetchDocument(ctx context.Context, accountID string) (Document[AccountData], error) {
+	doc, err := o.api.FetchDocument(ctx, accountID)
+	if err != nil {
+		return Document[AccountData]{}, err
+	}
+	if err := o.check(doc.Data); err != nil {
+		return Document[AccountData]{}, err
+	}
+	return doc, nil
+}
+
+func (o *organisationAPI) List(ctx context.Context, options ListOptions, fn func(AccountData) error) error {
+	_, err := o.ListPage(ctx, options, fn)
+	return err
+}
+
+func (o *organisationAPI) ListPage(ctx context.Context, options ListOptions, fn func(AccountData) error) (Page, error) {
+	filter := make(map[string]string, len(options.Filter)+1)
+	for k, v := range options.Filter {
+		filter[k] = v
+	}
+	filter["organisation_id"] = o.organisationID
+	options.Filter = filter
+
+	return o.api.ListPage(ctx, options, func(data AccountData) error {
+		if err := o.check(data); err != nil {
+			return err
+		}
+		return fn(data)
+	})
+}
+
+// Delete fetches the account first, to make sure it belongs to the
+// organisation.
+func (o *organisationAPI) Delete(ctx context.Context, accountID string, version int64) error {
+	if _, err := o.FetchDocument(ctx, accountID); err != nil {
+		return err
+	}
+	return o.api.Delete(ctx, accountID, version)
+}
+
+func (o *organisationAPI) Health(ctx context.Context) (HealthStatus, error) {
+	return o.api.Health(ctx)
+}
@@ -0,0 +1,147 @@
+package form3api
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// testTenantServer serves accounts of multiple organisations, ignoring list
+// filters.
+type testTenantServer struct {
+	mu       sync.Mutex
+	accounts map[string]string
+	requests []string
+}
+
+func (s *testTenantServer) client() *http.Client {
+	return &http.Client{
+		Transport: &testRoundTripper{
+			roundTrip: func(req *http.Request) (*http.Response, error) {
+				s.mu.Lock()
+				defer s.mu.Unlock()
+
+				s.requests = append(s.requests, req.Method+" "+req.URL.RequestURI())
+
+				respond := func(statusCode int, body string) (*http.Response, error) {
+					return &http.Response{
+						StatusCode: statusCode,
+						Body:       io.NopCloser(bytes.NewBufferString(body)),
+						Request:    req,
+					}, nil
+				}
+
+				id := path.Base(req.URL.Path)
+				switch {
+				case req.Method == http.MethodPost:
+					body, err := io.ReadAll(req.Body)
+					if err != nil {
+						return nil, err
+					}
+					return respond(201, string(body))
+				case req.Method == http.MethodDelete:
+					return respond(204, "")
+				case id == "accounts":
+					var data []string
+					for id, org := range s.accounts {
+						data = append(data, fmt.Sprintf(`{"id": %q, "organisation_id": %q}`, id, org))
+					}
+					return respond(200, `{"data": [`+strings.Join(data, ",")+`]}`)
+				}
+
+				org, ok := s.accounts[id]
+				if !ok {
+					return respond(404, "")
+				}
+				return respond(200, fmt.Sprintf(`{"data": {"id": %q, "organisation_id": %q}}`, id, org))
+			},
+		},
+	}
+}
+
+func TestOrganisationAPI(t *testing.T) {
+	s := &testTenantServer{
+		accounts: map[string]string{
+			"a1": "org1",
+			"a2": "org2",
+		},
+	}
+	client := NewMultiTenantAPI(WithHttpClient(s.client()))
+	org1 := client.ForOrganisation("org1")
+	ctx := context.Background()
+
+	var mismatch *ErrOrganisationMismatch
+
+	if _, err := org1.Fetch(ctx, "a1"); err != nil {
+		t.Error("no error expected, got:", err)
+	}
+	if _, err := org1.Fetch(ctx, "a2"); !errors.As(err, &mismatch) || mismatch.Actual != "org2" {
+		t.Error("expected organisation mismatch error, got:", err)
+	}
+
+	data, err := org1.Create(ctx, AccountData{ID: "a3"})
+	if err != nil {
+		t.Fatal("no error expected, got:", err)
+	}
+	if data.OrganisationID != "org1" {
+		t.Error("unexpected organisation id:", data.OrganisationID)
+	}
+	if _, err := org1.Create(ctx, AccountData{ID: "a4", OrganisationID: "org2"}); !errors.As(err, &mismatch) {
+		t.Error("expected organisation mismatch error, got:", err)
+	}
+
+	if err := org1.Delete(ctx, "a2", 0); !errors.As(err, &mismatch) {
+		t.Error("expected organisation mismatch error, got:", err)
+	}
+	if err := org1.Delete(ctx, "a1", 0); err != nil {
+		t.Error("no error expected, got:", err)
+	}
+
+	err = org1.List(ctx, ListOptions{}, func(AccountData) error { return nil })
+	if !errors.As(err, &mismatch) {
+		t.Error("expected organisation mismatch error, got:", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var deletes, creates int
+	for _, r := range s.requests {
+		switch {
+		case strings.HasPrefix(r, http.MethodDelete):
+			deletes++
+			if r != "DELETE /v1/organisation/accounts/a1?version=0" {
+				t.Error("unexpected delete:", r)
+			}
+		case strings.HasPrefix(r, "GET /v1/organisation/accounts?"):
+			if !strings.Contains(r, "filter[organisation_id]=org1") {
+				t.Error("expected organisation filter:", r)
+			}
+		case strings.HasPrefix(r, http.MethodPost):
+			creates++
+		}
+	}
+	if creates != 1 {
+		t.Error("expected cross-tenant account not to be sent, creates:", creates)
+	}
+	if deletes != 1 {
+		t.Error("expected a single delete, got:", deletes)
+	}
+}
+
+func TestOrganisationAPISharesClient(t *testing.T) {
+	client := NewMultiTenantAPI(WithRateLimit(10, 1))
+
+	org1 := client.ForOrganisation("org1").(*organisationAPI)
+	org2 := client.ForOrganisation("org2").(*organisationAPI)
+	if org1.api != org2.api {
+		t.Error("expected views to share the client")
+	}
+}
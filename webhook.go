@@ -0,0 +1,190 @@
+package form3api
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+const (
+	DefaultSignatureHeader = "X-Form3-Signature"
+
+	// Maximum size of a webhook request body.
+	maxWebhookBodySize = 1 << 20
+)
+
+// ErrInvalidSignature is returned by a Verifier, when a webhook request
+// wasn't signed with the expected key.
+type ErrInvalidSignature struct{}
+
+func (e ErrInvalidSignature) Error() string {
+	return "invalid signature"
+}
+
+// Verifier checks whether a webhook request comes from Form3.
+type Verifier interface {
+	Verify(r *http.Request, body []byte) error
+}
+
+// HMACVerifier verifies hex-encoded HMAC-SHA256 signatures of webhook request
+// bodies. The signature may be prefixed with "sha256=".
+type HMACVerifier struct {
+	Secret []byte
+	// Header holding the signature. Defaults to DefaultSignatureHeader.
+	Header string
+}
+
+func (v HMACVerifier) Verify(r *http.Request, body []byte) error {
+	header := v.Header
+	if len(header) == 0 {
+		header = DefaultSignatureHeader
+	}
+
+	signature, err := hex.DecodeString(
+		strings.TrimPrefix(r.Header.Get(header), "sha256="),
+	)
+	if err != nil || len(signature) == 0 {
+		return new(ErrInvalidSignature)
+	}
+
+	mac := hmac.New(sha256.New, v.Secret)
+	mac.Write(body)
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return new(ErrInvalidSignature)
+	}
+	return nil
+}
+
+type insecureSkipVerify struct{}
+
+func (insecureSkipVerify) Verify(*http.Request, []byte) error {
+	return nil
+}
+
+// InsecureSkipVerify accepts all webhook requests, signed or not. It should
+// only be used when requests are authenticated otherwise, for ex. with mutual
+// TLS.
+var InsecureSkipVerify Verifier = insecureSkipVerify{}
+
+// AccountEvent is a notification about a change of an account.
+type AccountEvent struct {
+	ID             string      `json:"id"`
+	OrganisationID string      `json:"organisation_id"`
+	EventType      string      `json:"event_type"`
+	ResourceType   string      `json:"resource_type"`
+	Version        int64       `json:"version"`
+	Data           AccountData `json:"data"`
+}
+
+// WebhookHandler is an http.Handler receiving notifications of subscriptions
+// created with SubscriptionsAPI. Verified events are dispatched to the
+// registered handlers. When any of them fails, the request is answered with
+// 500, so that the notification is delivered again.
+type WebhookHandler struct {
+	verifier Verifier
+
+	mu       sync.RWMutex
+	accounts map[string][]func(context.Context, AccountEvent) error
+}
+
+// NewWebhookHandler creates a WebhookHandler checking requests with verifier.
+// It panics when verifier is nil, use InsecureSkipVerify to accept unsigned
+// requests.
+func NewWebhookHandler(verifier Verifier) *WebhookHandler {
+	if verifier == nil {
+		panic("form3api: nil webhook verifier")
+	}
+	return &WebhookHandler{
+		verifier: verifier,
+		accounts: make(map[string][]func(context.Context, AccountEvent) error),
+	}
+}
+
+// HandleAccountEvent registers fn to be called with account events of the
+// eventType. Empty eventType matches all events.
+func (h *WebhookHandler) HandleAccountEvent(eventType string, fn func(context.Context, AccountEvent) error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.accounts[eventType] = append(h.accounts[eventType], fn)
+}
+
+func (h *WebhookHandler) accountHandlers(eventType string) []func(context.Context, AccountEvent) error {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	var ret []func(context.Context, AccountEvent) error
+	ret = append(ret, h.accounts[""]...)
+	if len(eventType) > 0 {
+		ret = append(ret, h.accounts[eventType]...)
+	}
+	return ret
+}
+
+func (h *WebhookHandler) dispatch(ctx context.Context, body []byte) (int, error) {
+	var event struct {
+		ResourceType string `json:"resource_type"`
+	}
+	if err := json.Unmarshal(body, &event); err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	switch event.ResourceType {
+	case RecordTypeAccounts:
+		var e AccountEvent
+		if err := json.Unmarshal(body, &e); err != nil {
+			return http.StatusBadRequest, err
+		}
+		for _, fn := range h.accountHandlers(e.EventType) {
+			if err := fn(ctx, e); err != nil {
+				return http.StatusInternalServerError, err
+			}
+		}
+	}
+	// Events nobody is interested in are acknowledged, so that they aren't
+	// delivered again.
+	return http.StatusNoContent, nil
+}
+
+func (h *WebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookBodySize))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.verifier.Verify(r, body); err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	status, err := h.dispatch(r.Context(), body)
+	if err != nil {
+		message := err.Error()
+		if status >= 500 {
+			// Don't leak errors of the handlers.
+			message = http.StatusText(status)
+		}
+		http.Error(w, message, status)
+		return
+	}
+	w.WriteHeader(status)
+}
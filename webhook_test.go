@@ -0,0 +1,121 @@
+package form3api
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const testAccountEventMessage = `{
+	"id": "e1",
+	"organisation_id": "ba61483c-d5c5-4f50-ae81-6b8c039bea43",
+	"event_type": "updated",
+	"resource_type": "accounts",
+	"version": 3,
+	"data": {
+		"id": "0d209d7f-d07a-4542-947f-5885fddddae2",
+		"type": "accounts",
+		"attributes": {"status": "closed"}
+	}
+}`
+
+func signBody(secret, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func postWebhook(h http.Handler, body, signature string) int {
+	req := httptest.NewRequest(http.MethodPost, "/hook", strings.NewReader(body))
+	if len(signature) > 0 {
+		req.Header.Set(DefaultSignatureHeader, signature)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func TestWebhookHandlerDispatch(t *testing.T) {
+	h := NewWebhookHandler(HMACVerifier{Secret: []byte("secret")})
+
+	var all, updated, deleted []AccountEvent
+	h.HandleAccountEvent("", func(_ context.Context, e AccountEvent) error {
+		all = append(all, e)
+		return nil
+	})
+	h.HandleAccountEvent(EventUpdated, func(_ context.Context, e AccountEvent) error {
+		updated = append(updated, e)
+		return nil
+	})
+	h.HandleAccountEvent(EventDeleted, func(_ context.Context, e AccountEvent) error {
+		deleted = append(deleted, e)
+		return nil
+	})
+
+	signature := "sha256=" + signBody("secret", testAccountEventMessage)
+	if code := postWebhook(h, testAccountEventMessage, signature); code != http.StatusNoContent {
+		t.Fatal("unexpected status code:", code)
+	}
+
+	if len(all) != 1 || len(updated) != 1 || len(deleted) != 0 {
+		t.Fatalf("unexpected dispatch: %d, %d, %d", len(all), len(updated), len(deleted))
+	}
+	e := updated[0]
+	if e.Version != 3 || e.Data.ID != "0d209d7f-d07a-4542-947f-5885fddddae2" {
+		t.Errorf("unexpected event: %+v", e)
+	}
+	if e.Data.Attributes == nil || *e.Data.Attributes.Status != "closed" {
+		t.Error("unexpected account attributes:", e.Data.Attributes)
+	}
+}
+
+func TestWebhookHandlerRejects(t *testing.T) {
+	h := NewWebhookHandler(HMACVerifier{Secret: []byte("secret")})
+	h.HandleAccountEvent("", func(context.Context, AccountEvent) error {
+		return errors.New("foo")
+	})
+
+	for _, test := range []struct {
+		body      string
+		signature string
+		expected  int
+	}{
+		{body: testAccountEventMessage, signature: "", expected: http.StatusUnauthorized},
+		{body: testAccountEventMessage, signature: signBody("other", testAccountEventMessage), expected: http.StatusUnauthorized},
+		{body: "{", signature: signBody("secret", "{"), expected: http.StatusBadRequest},
+		{body: testAccountEventMessage, signature: signBody("secret", testAccountEventMessage), expected: http.StatusInternalServerError},
+		{body: `{"resource_type": "payments"}`, signature: signBody("secret", `{"resource_type": "payments"}`), expected: http.StatusNoContent},
+	} {
+		if code := postWebhook(h, test.body, test.signature); code != test.expected {
+			t.Errorf("%q: expected status code %d, got %d", test.body, test.expected, code)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/hook", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Error("unexpected status code:", rec.Code)
+	}
+}
+
+func TestWebhookHandlerVerifier(t *testing.T) {
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected nil verifier to be rejected")
+			}
+		}()
+		NewWebhookHandler(nil)
+	}()
+
+	h := NewWebhookHandler(InsecureSkipVerify)
+	if code := postWebhook(h, testAccountEventMessage, ""); code != http.StatusNoContent {
+		t.Error("unexpected status code:", code)
+	}
+}